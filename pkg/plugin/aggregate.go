@@ -0,0 +1,144 @@
+package plugin
+
+import (
+	"fmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type reduceFunc func(values []float64) float64
+
+// aggregateGroupBy are the resource attributes the series can be grouped by.
+var aggregateGroupBy = []string{"tag", "zone", "region"}
+
+// aggregateSeries combines the series of the same metric and group into one series, the values are
+// aligned on the buckets of the period. The groups are split by the resource attributes of groupBy.
+func aggregateSeries(series []*metricSeries, aggregation string, groupBy []string, period int64) ([]*metricSeries, error) {
+	reduce, err := getReduceFunc(aggregation)
+	if err != nil {
+		return nil, err
+	}
+	for _, k := range groupBy {
+		if !containsString(aggregateGroupBy, k) {
+			return nil, fmt.Errorf("groupBy is invalid, must set to tag, zone or region, got %s", k)
+		}
+	}
+
+	type group struct {
+		series *metricSeries
+		values map[int64][]float64
+	}
+	var keys []string
+	groups := map[string]*group{}
+	for _, s := range series {
+		key := s.Metric
+		name := aggregation
+		labels := data.Labels{"aggregation": aggregation}
+		for _, k := range groupBy {
+			v, _ := s.Resource.attribute(k)
+			key += "\x00" + v
+			name += fmt.Sprintf(" %s=%s", k, v)
+			labels[k] = v
+		}
+
+		g, ok := groups[key]
+		if !ok {
+			g = &group{
+				series: &metricSeries{Name: name, Metric: s.Metric, Labels: labels},
+				values: map[int64][]float64{},
+			}
+			groups[key] = g
+			keys = append(keys, key)
+		}
+		// the points of the resources in the same period may be collected a few seconds apart
+		for i, t := range s.Times {
+			ts := t.Unix() / period * period
+			g.values[ts] = append(g.values[ts], s.Values[i])
+		}
+	}
+
+	result := make([]*metricSeries, 0, len(keys))
+	for _, key := range keys {
+		g := groups[key]
+		timestamps := make([]int64, 0, len(g.values))
+		for ts := range g.values {
+			timestamps = append(timestamps, ts)
+		}
+		sort.Slice(timestamps, func(i, j int) bool { return timestamps[i] < timestamps[j] })
+
+		g.series.Times = make([]time.Time, 0, len(timestamps))
+		g.series.Values = make([]float64, 0, len(timestamps))
+		for _, ts := range timestamps {
+			g.series.Times = append(g.series.Times, time.Unix(ts, 0))
+			g.series.Values = append(g.series.Values, reduce(g.values[ts]))
+		}
+		result = append(result, g.series)
+	}
+	return result, nil
+}
+
+// getReduceFunc returns the function to reduce the values by aggregation name,
+// supports sum, avg, min, max, count and percentile like p95.
+func getReduceFunc(aggregation string) (reduceFunc, error) {
+	switch aggregation {
+	case "sum":
+		return sum, nil
+	case "avg":
+		return func(values []float64) float64 {
+			return sum(values) / float64(len(values))
+		}, nil
+	case "min":
+		return func(values []float64) float64 {
+			result := values[0]
+			for _, v := range values[1:] {
+				result = math.Min(result, v)
+			}
+			return result
+		}, nil
+	case "max":
+		return func(values []float64) float64 {
+			result := values[0]
+			for _, v := range values[1:] {
+				result = math.Max(result, v)
+			}
+			return result
+		}, nil
+	case "count":
+		return func(values []float64) float64 {
+			return float64(len(values))
+		}, nil
+	}
+
+	if strings.HasPrefix(aggregation, "p") {
+		if p, err := strconv.ParseFloat(aggregation[1:], 64); err == nil && p > 0 && p <= 100 {
+			return func(values []float64) float64 {
+				return percentile(values, p)
+			}, nil
+		}
+	}
+	return nil, fmt.Errorf("aggregation is invalid, must set to sum, avg, min, max, count or percentile like p95, got %s", aggregation)
+}
+
+func sum(values []float64) float64 {
+	var result float64
+	for _, v := range values {
+		result += v
+	}
+	return result
+}
+
+// percentile returns the p-th percentile of the values by linear interpolation between the closest ranks.
+func percentile(values []float64, p float64) float64 {
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	rank := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
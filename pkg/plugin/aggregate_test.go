@@ -0,0 +1,77 @@
+package plugin
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAggregateSeries(t *testing.T) {
+	t0, t1, t2 := time.Unix(60, 0), time.Unix(120, 0), time.Unix(180, 0)
+	// the points of the same period collected a few seconds apart
+	t1Late := time.Unix(123, 0)
+	series := []*metricSeries{
+		{
+			Metric:   "NetworkOut",
+			Resource: resource{Id: "eip-1", Tag: "web"},
+			Times:    []time.Time{t0, t1},
+			Values:   []float64{1, 2},
+		},
+		{
+			Metric:   "NetworkOut",
+			Resource: resource{Id: "eip-2", Tag: "web"},
+			Times:    []time.Time{t1Late, t2},
+			Values:   []float64{3, 4},
+		},
+		{
+			Metric:   "NetworkOut",
+			Resource: resource{Id: "eip-3", Tag: "db"},
+			Times:    []time.Time{t0},
+			Values:   []float64{5},
+		},
+	}
+
+	result, err := aggregateSeries(series, "sum", nil, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 1 {
+		t.Fatalf("expected 1 series, got %d", len(result))
+	}
+	if !reflect.DeepEqual(result[0].Times, []time.Time{t0, t1, t2}) {
+		t.Errorf("expected aligned times, got %v", result[0].Times)
+	}
+	if !reflect.DeepEqual(result[0].Values, []float64{6, 5, 4}) {
+		t.Errorf("expected sum values, got %v", result[0].Values)
+	}
+
+	result, err = aggregateSeries(series, "max", []string{"tag"}, 60)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result) != 2 || result[0].Labels["tag"] != "web" || result[1].Labels["tag"] != "db" {
+		t.Fatalf("expected series grouped by tag, got %v", result)
+	}
+	if !reflect.DeepEqual(result[0].Values, []float64{1, 3, 4}) {
+		t.Errorf("expected max values, got %v", result[0].Values)
+	}
+
+	if _, err = aggregateSeries(series, "median", nil, 60); err == nil {
+		t.Error("expected error of invalid aggregation")
+	}
+	for _, groupBy := range []string{"vpc", "resourceId"} {
+		if _, err = aggregateSeries(series, "sum", []string{groupBy}, 60); err == nil {
+			t.Errorf("expected error of invalid groupBy %s", groupBy)
+		}
+	}
+}
+
+func TestPercentile(t *testing.T) {
+	values := []float64{4, 1, 3, 2, 5}
+	cases := map[float64]float64{50: 3, 100: 5, 25: 2, 90: 4.6}
+	for p, expected := range cases {
+		if v := percentile(values, p); v != expected {
+			t.Errorf("p%v expected %v, got %v", p, expected, v)
+		}
+	}
+}
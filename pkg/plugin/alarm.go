@@ -0,0 +1,209 @@
+package plugin
+
+import (
+	"fmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// alarmPageSize is the page size of DescribeAlarmHistory
+	alarmPageSize = 100
+	// maxAlarmEvents caps the alarm events of each resource id of a query
+	maxAlarmEvents = 1000
+)
+
+// alarmEvent is an alarm of UMon, End is zero if the alarm is not recovered yet.
+type alarmEvent struct {
+	Start time.Time
+	End   time.Time
+	Title string
+	Text  string
+	Tags  []string
+}
+
+// alarmHistory is the alarm of the DescribeAlarmHistory response, the times are unix timestamps and
+// RecoverTime is zero if the alarm is not recovered yet.
+type alarmHistory struct {
+	ResourceId        string
+	ResourceName      string
+	MetricName        string
+	AlarmStrategyName string
+	AlarmLevel        string
+	AlarmStatus       string
+	Content           string
+	AlarmTime         int64
+	RecoverTime       int64
+}
+
+// queryAlarms returns the alarm history of the resources in the time range as the annotation frame.
+func (d *UCloudDatasource) queryAlarms(client *uCloudClient, qm queryModel, timeRange backend.TimeRange) backend.DataResponse {
+	response := backend.DataResponse{}
+
+	events, truncated, err := client.describeAlarmHistory(qm, timeRange.From.Unix(), timeRange.To.Unix())
+	if err != nil {
+		response.Error = fmt.Errorf("describe alarm history got error, %s", panelError(err))
+		return response
+	}
+	frame := alarmFrame(events)
+	if truncated {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("only the first %d alarms of each resource are shown, narrow down the time range to see the others", maxAlarmEvents),
+		})
+	}
+	response.Frames = append(response.Frames, frame)
+	return response
+}
+
+// describeAlarmHistory returns the alarm history of the resource type in [begin, end]. The alarms of each
+// resource id are queried separately, so the cap of maxAlarmEvents applies to each resource instead of
+// the whole region. The whole region is queried if resourceId is `*` or not set.
+func (client *uCloudClient) describeAlarmHistory(qm queryModel, begin, end int64) ([]alarmEvent, bool, error) {
+	ids := []string{""}
+	if len(qm.ResourceId) != 0 && !containsString(qm.ResourceId, "*") {
+		ids = qm.ResourceId
+	}
+
+	var events []alarmEvent
+	var truncated bool
+	for _, id := range ids {
+		alarms, more, err := client.describeResourceAlarms(qm, id, begin, end)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, alarm := range alarms {
+			// the alarms without the start time can not be placed on the time axis
+			if alarm.AlarmTime <= 0 || (id != "" && alarm.ResourceId != id) {
+				continue
+			}
+			events = append(events, newAlarmEvent(qm.ResourceType, alarm))
+		}
+		truncated = truncated || more
+	}
+
+	sort.SliceStable(events, func(i, j int) bool { return events[i].Start.Before(events[j].Start) })
+	return events, truncated, nil
+}
+
+// describeResourceAlarms pages through DescribeAlarmHistory of the resource id, the alarms beyond
+// maxAlarmEvents are dropped and more is true.
+func (client *uCloudClient) describeResourceAlarms(qm queryModel, resourceId string, begin, end int64) (alarms []alarmHistory, more bool, err error) {
+	for offset := 0; ; offset += alarmPageSize {
+		if offset >= maxAlarmEvents {
+			return alarms, true, nil
+		}
+		req := client.ucloudconn.NewGenericRequest()
+		if qm.ProjectId != "" {
+			_ = req.SetProjectId(qm.ProjectId)
+		}
+		reqMap := map[string]interface{}{
+			"Action":    "DescribeAlarmHistory",
+			"Region":    qm.Region,
+			"BeginTime": begin,
+			"EndTime":   end,
+			"Limit":     alarmPageSize,
+			"Offset":    offset,
+		}
+		if qm.ResourceType != "" {
+			reqMap["ResourceType"] = qm.ResourceType
+		}
+		if resourceId != "" {
+			reqMap["ResourceId"] = resourceId
+		}
+		if err := req.SetPayload(reqMap); err != nil {
+			return nil, false, fmt.Errorf("set DescribeAlarmHistory request got err, %s", err)
+		}
+
+		resp, err := client.ucloudconn.GenericInvoke(req)
+		if err != nil {
+			return nil, false, err
+		}
+
+		type DescribeAlarmHistoryResponse struct {
+			TotalCount int
+			DataSet    []alarmHistory
+		}
+		respDescribe := &DescribeAlarmHistoryResponse{}
+		if err := resp.Unmarshal(respDescribe); err != nil {
+			return nil, false, fmt.Errorf("unmarshal DescribeAlarmHistory resp got err, %s", err)
+		}
+		alarms = append(alarms, respDescribe.DataSet...)
+		if len(respDescribe.DataSet) < alarmPageSize || (respDescribe.TotalCount > 0 && offset+alarmPageSize >= respDescribe.TotalCount) {
+			return alarms, false, nil
+		}
+	}
+}
+
+func newAlarmEvent(resourceType string, alarm alarmHistory) alarmEvent {
+	title := alarm.AlarmStrategyName
+	if alarm.MetricName != "" {
+		title = strings.TrimSpace(fmt.Sprintf("%s %s", title, alarm.MetricName))
+	}
+	if alarm.AlarmLevel != "" {
+		title = fmt.Sprintf("[%s] %s", alarm.AlarmLevel, title)
+	}
+
+	var text []string
+	if alarm.Content != "" {
+		text = append(text, alarm.Content)
+	}
+	target := alarm.ResourceId
+	if alarm.ResourceName != "" {
+		target = fmt.Sprintf("%s (%s)", alarm.ResourceName, alarm.ResourceId)
+	}
+	if target != "" {
+		text = append(text, "resource: "+target)
+	}
+
+	var tags []string
+	for _, tag := range []string{resourceType, alarm.ResourceId, alarm.MetricName, alarm.AlarmLevel, alarm.AlarmStatus} {
+		if tag != "" {
+			tags = append(tags, tag)
+		}
+	}
+
+	e := alarmEvent{
+		Start: time.Unix(alarm.AlarmTime, 0),
+		Title: title,
+		Text:  strings.Join(text, "\n"),
+		Tags:  tags,
+	}
+	if alarm.RecoverTime > 0 {
+		e.End = time.Unix(alarm.RecoverTime, 0)
+	}
+	return e
+}
+
+// alarmFrame returns the frame of the alarm events in the fields of the Grafana annotations,
+// timeEnd is null if the alarm is not recovered and the tags are comma separated.
+func alarmFrame(events []alarmEvent) *data.Frame {
+	times := make([]time.Time, 0, len(events))
+	timeEnds := make([]*time.Time, 0, len(events))
+	titles := make([]string, 0, len(events))
+	texts := make([]string, 0, len(events))
+	tags := make([]string, 0, len(events))
+	for _, e := range events {
+		times = append(times, e.Start)
+		var end *time.Time
+		if !e.End.IsZero() {
+			t := e.End
+			end = &t
+		}
+		timeEnds = append(timeEnds, end)
+		titles = append(titles, e.Title)
+		texts = append(texts, e.Text)
+		tags = append(tags, strings.Join(e.Tags, ","))
+	}
+
+	return data.NewFrame("alarms",
+		data.NewField("time", nil, times),
+		data.NewField("timeEnd", nil, timeEnds),
+		data.NewField("title", nil, titles),
+		data.NewField("text", nil, texts),
+		data.NewField("tags", nil, tags),
+	)
+}
@@ -0,0 +1,67 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDescribeAlarmHistory(t *testing.T) {
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		_, _ = rw.Write([]byte(`{"Action":"DescribeAlarmHistoryResponse","RetCode":0,"DataSet":[
+			{"ResourceId":"uhost-b","AlarmTime":1600000600,"MetricName":"MemUsage"},
+			{"ResourceId":"uhost-a","MetricName":"MemUsage"},
+			{"ResourceId":"uhost-a","ResourceName":"web","AlarmTime":1600000000,"RecoverTime":1600000300,
+				"MetricName":"CPUUtilization","AlarmLevel":"P1","AlarmStrategyName":"cpu","Content":"CPU > 90%","AlarmStatus":"Recovered"}]}`))
+	})
+	events, truncated, err := client.describeAlarmHistory(queryModel{Region: "cn-bj2", ResourceType: "uhost", ResourceId: stringList{"uhost-a"}}, 1600000000, 1600003600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 1 || truncated {
+		t.Fatalf("expected the alarms of uhost-a, got %+v", events)
+	}
+	e := events[0]
+	if e.Title != "[P1] cpu CPUUtilization" || e.Text != "CPU > 90%\nresource: web (uhost-a)" || !e.End.Equal(time.Unix(1600000300, 0)) {
+		t.Errorf("unexpected event %+v", e)
+	}
+
+	frame := alarmFrame(events)
+	if frame.Rows() != 1 || len(frame.Fields) != 5 {
+		t.Fatalf("unexpected frame %+v", frame)
+	}
+	if tags, _ := frame.ConcreteAt(4, 0); tags != "uhost,uhost-a,CPUUtilization,P1,Recovered" {
+		t.Errorf("unexpected tags %v", tags)
+	}
+}
+
+func TestDescribeAlarmHistoryPerResource(t *testing.T) {
+	calls := map[string]int{}
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		id := req.Form.Get("ResourceId")
+		calls[id]++
+		// uhost-a has more alarms than the cap, uhost-b has a single page
+		count, total := alarmPageSize, 5000
+		if id == "uhost-b" {
+			count, total = 2, 2
+		}
+		alarms := make([]string, 0, count)
+		for i := 0; i < count; i++ {
+			alarms = append(alarms, fmt.Sprintf(`{"ResourceId":"%s","AlarmTime":%d}`, id, 1600000000+i))
+		}
+		_, _ = fmt.Fprintf(rw, `{"RetCode":0,"TotalCount":%d,"DataSet":[%s]}`, total, strings.Join(alarms, ","))
+	})
+	events, truncated, err := client.describeAlarmHistory(queryModel{Region: "cn-bj2", ResourceType: "uhost", ResourceId: stringList{"uhost-a", "uhost-b"}}, 1600000000, 1600003600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != maxAlarmEvents+2 || !truncated {
+		t.Errorf("expected the capped alarms of uhost-a and all of uhost-b, got %d, truncated %v", len(events), truncated)
+	}
+	if calls["uhost-a"] != maxAlarmEvents/alarmPageSize || calls["uhost-b"] != 1 || calls[""] != 0 {
+		t.Errorf("expected the alarms queried by each resource id, got %v", calls)
+	}
+}
@@ -0,0 +1,121 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	uerr "github.com/ucloud/ucloud-sdk-go/ucloud/error"
+	"net/http"
+	"strings"
+)
+
+// the codes of the error envelope of the resource API
+const (
+	errCodeBadRequest   = "BadRequest"
+	errCodeUnauthorized = "Unauthorized"
+	errCodeForbidden    = "Forbidden"
+	errCodeUpstream     = "UpstreamError"
+	errCodeTimeout      = "Timeout"
+	errCodeInternal     = "InternalError"
+)
+
+// apiError is the JSON error envelope of the resource API, RetCode, RequestId and Action are
+// set if the error is returned by the UCloud API.
+type apiError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	RetCode   int    `json:"retCode,omitempty"`
+	RequestId string `json:"requestId,omitempty"`
+	Action    string `json:"action,omitempty"`
+
+	status int
+}
+
+func (e *apiError) Error() string {
+	return e.Message
+}
+
+func newBadRequestError(format string, args ...interface{}) *apiError {
+	return &apiError{
+		Code:    errCodeBadRequest,
+		Message: fmt.Sprintf(format, args...),
+		status:  http.StatusBadRequest,
+	}
+}
+
+// newAPIError classifies the error of the UCloud API action by the RetCode and message.
+func newAPIError(action string, err error) *apiError {
+	var e *apiError
+	if errors.As(err, &e) {
+		return e
+	}
+
+	e = &apiError{
+		Code:    errCodeUpstream,
+		Message: err.Error(),
+		Action:  action,
+		status:  http.StatusBadGateway,
+	}
+	if isCancelled(err) {
+		e.Code, e.status = errCodeTimeout, http.StatusGatewayTimeout
+		return e
+	}
+
+	var ucErr *ucloudError
+	if errors.As(err, &ucErr) {
+		e.Message = panelError(ucErr)
+		e.RequestId = ucErr.RequestUUID
+		if ucErr.Action != "" {
+			e.Action = ucErr.Action
+		}
+	}
+
+	var uErr uerr.Error
+	if !errors.As(err, &uErr) || uErr.Name() != uerr.ErrRetCode {
+		return e
+	}
+	e.RetCode = uErr.Code()
+	if ucErr == nil {
+		e.Message = uErr.Message()
+	}
+	switch {
+	case isSignatureError(uErr):
+		e.Code, e.status = errCodeUnauthorized, http.StatusUnauthorized
+	case isPermissionError(uErr):
+		e.Code, e.status = errCodeForbidden, http.StatusForbidden
+	case isParamError(uErr):
+		e.Code, e.status = errCodeBadRequest, http.StatusBadRequest
+	}
+	return e
+}
+
+func isSignatureError(e uerr.Error) bool {
+	message := strings.ToLower(e.Message())
+	return e.Code() == retCodeSignatureError || strings.Contains(message, "signature") || strings.Contains(message, "public key")
+}
+
+func isPermissionError(e uerr.Error) bool {
+	message := strings.ToLower(e.Message())
+	return strings.Contains(message, "permission") || strings.Contains(message, "no auth")
+}
+
+func isParamError(e uerr.Error) bool {
+	message := strings.ToLower(e.Message())
+	return strings.Contains(message, "param") || strings.Contains(message, "missing")
+}
+
+// writeError writes the error envelope with the status code, the unclassified errors are internal errors.
+func writeError(rw http.ResponseWriter, err error) {
+	var e *apiError
+	if !errors.As(err, &e) {
+		e = &apiError{Code: errCodeInternal, Message: err.Error(), status: http.StatusInternalServerError}
+	}
+	body, _ := json.Marshal(e)
+
+	rw.Header().Set("Content-Type", "application/json")
+	rw.WriteHeader(e.status)
+	if _, err := rw.Write(body); err != nil {
+		log.DefaultLogger.Error("write response got error", "error", err)
+	}
+}
@@ -0,0 +1,64 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	uerr "github.com/ucloud/ucloud-sdk-go/ucloud/error"
+)
+
+func TestNewAPIError(t *testing.T) {
+	for _, c := range []struct {
+		err    error
+		code   string
+		status int
+	}{
+		{newBadRequestError("missing parameter Action"), errCodeBadRequest, http.StatusBadRequest},
+		{uerr.NewServerCodeError(171, "Signature VerifyAC Error"), errCodeUnauthorized, http.StatusUnauthorized},
+		{uerr.NewServerCodeError(172, "No Permission"), errCodeForbidden, http.StatusForbidden},
+		{uerr.NewServerCodeError(230, "Params [Region] not available"), errCodeBadRequest, http.StatusBadRequest},
+		{uerr.NewServerCodeError(5000, "Internal Error"), errCodeUpstream, http.StatusBadGateway},
+		{uerr.NewServerStatusError(503, "503 Service Unavailable"), errCodeUpstream, http.StatusBadGateway},
+		{fmt.Errorf("describe got error, %w", uerr.NewServerCodeError(172, "No Permission")), errCodeForbidden, http.StatusForbidden},
+		{uerr.NewClientError(uerr.ErrSendRequest, context.DeadlineExceeded), errCodeTimeout, http.StatusGatewayTimeout},
+		{uerr.NewClientError(uerr.ErrNetwork, fmt.Errorf("send request got error, %w", context.Canceled)), errCodeTimeout, http.StatusGatewayTimeout},
+	} {
+		e := newAPIError("GetMetric", c.err)
+		if e.Code != c.code || e.status != c.status {
+			t.Errorf("error %q, got %s %d, want %s %d", c.err, e.Code, e.status, c.code, c.status)
+		}
+	}
+}
+
+func TestWriteError(t *testing.T) {
+	rw := httptest.NewRecorder()
+	handleResponse(rw, nil, newAPIError("DescribeUHostInstance", uerr.NewServerCodeError(171, "Signature VerifyAC Error")))
+	if rw.Code != http.StatusUnauthorized || rw.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("unexpected response %d %s", rw.Code, rw.Header().Get("Content-Type"))
+	}
+	var e apiError
+	if err := json.Unmarshal(rw.Body.Bytes(), &e); err != nil {
+		t.Fatal(err)
+	}
+	if e.Code != errCodeUnauthorized || e.RetCode != 171 || e.Action != "DescribeUHostInstance" {
+		t.Errorf("unexpected error envelope %+v", e)
+	}
+
+	rw = httptest.NewRecorder()
+	handleResponse(rw, nil, errors.New("unexpected"))
+	if rw.Code != http.StatusInternalServerError {
+		t.Errorf("expected internal error, got %d", rw.Code)
+	}
+}
+
+func TestPanelErrorCancelled(t *testing.T) {
+	err := uerr.NewClientError(uerr.ErrSendRequest, context.Canceled)
+	if got := panelError(err); got != "the query is cancelled or timed out" {
+		t.Errorf("expected the cancelled message, got %s", got)
+	}
+}
@@ -0,0 +1,99 @@
+package plugin
+
+import (
+	"net/url"
+	"sync"
+	"time"
+)
+
+// paramNoCache is the param of the generic api to bypass the cache, the result is fetched and cached again.
+const paramNoCache = "NoCache"
+
+// defaultCacheTTLs are the ttl of the generic api results by action, the resources change more
+// frequently than the regions and the metric metadata.
+var defaultCacheTTLs = map[string]time.Duration{
+	ActionGetResourceId:   time.Minute,
+	ActionGetMetricName:   time.Hour,
+	ActionGetProjectId:    10 * time.Minute,
+	ActionGetRegion:       time.Hour,
+	ActionGetResourceType: time.Hour,
+}
+
+const (
+	// resourceLabelsTTL is how long the attributes of the explicit resource ids of the queries are cached,
+	// it is apart from the ttl of GetResourceId which may be disabled by the settings.
+	resourceLabelsTTL = 10 * time.Minute
+	// resourceLabelsFailureTTL is how long the failure of describing the explicit resource ids is cached.
+	resourceLabelsFailureTTL = time.Minute
+)
+
+// maxCacheEntries caps the entries of a cache, the expired entries are purged when it is full.
+const maxCacheEntries = 10000
+
+// ttlCache is an in-memory cache whose entries expire after the ttl set with them.
+type ttlCache struct {
+	mu      sync.Mutex
+	entries map[string]ttlCacheEntry
+}
+
+type ttlCacheEntry struct {
+	value     interface{}
+	expiredAt time.Time
+}
+
+func newTTLCache() *ttlCache {
+	return &ttlCache{
+		entries: map[string]ttlCacheEntry{},
+	}
+}
+
+// get returns the value of the key if it is cached and not expired.
+func (c *ttlCache) get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok || !time.Now().Before(entry.expiredAt) {
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// set caches the value of the key for ttl, it is a no-op if ttl is not positive.
+func (c *ttlCache) set(key string, value interface{}, ttl time.Duration) {
+	if ttl <= 0 {
+		return
+	}
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.entries) >= maxCacheEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expiredAt) {
+				delete(c.entries, k)
+			}
+		}
+		if len(c.entries) >= maxCacheEntries {
+			c.entries = map[string]ttlCacheEntry{}
+		}
+	}
+	c.entries[key] = ttlCacheEntry{value: value, expiredAt: now.Add(ttl)}
+}
+
+func (c *ttlCache) clear() {
+	c.mu.Lock()
+	c.entries = map[string]ttlCacheEntry{}
+	c.mu.Unlock()
+}
+
+// cacheKey normalizes the params to the cache key, the keys are sorted. The empty values are kept
+// as the handlers tell them from the absent ones, e.g. the empty Tag filters the untagged resources.
+func cacheKey(params map[string]string) string {
+	values := url.Values{}
+	for k, v := range params {
+		if k == paramNoCache {
+			continue
+		}
+		values.Set(k, v)
+	}
+	return values.Encode()
+}
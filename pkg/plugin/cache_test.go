@@ -0,0 +1,36 @@
+package plugin
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTTLCache(t *testing.T) {
+	c := newTTLCache()
+	c.set("a", 1, time.Minute)
+	c.set("b", 2, -time.Minute)
+	c.set("c", 3, time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+
+	if v, ok := c.get("a"); !ok || v.(int) != 1 {
+		t.Errorf("expected a is cached, got %v", v)
+	}
+	if _, ok := c.get("b"); ok {
+		t.Error("expected b is not cached")
+	}
+	if _, ok := c.get("c"); ok {
+		t.Error("expected c is expired")
+	}
+}
+
+func TestCacheKey(t *testing.T) {
+	a := cacheKey(map[string]string{"Action": "GetResourceId", "Region": "cn-bj2", "NoCache": "true"})
+	b := cacheKey(map[string]string{"Region": "cn-bj2", "Action": "GetResourceId"})
+	if a != b {
+		t.Errorf("expected the same key, got %s and %s", a, b)
+	}
+	// the empty Tag filters the untagged resources, it is not the same as all the resources
+	if c := cacheKey(map[string]string{"Action": "GetResourceId", "Region": "cn-bj2", "Tag": ""}); c == b {
+		t.Errorf("expected the empty Tag kept in the key, got %s", c)
+	}
+}
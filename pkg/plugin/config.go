@@ -1,14 +1,23 @@
 package plugin
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/json"
+	"fmt"
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/ucloud/ucloud-sdk-go/services/pathx"
 	"github.com/ucloud/ucloud-sdk-go/services/uaccount"
+	"github.com/ucloud/ucloud-sdk-go/services/ucdn"
 	"github.com/ucloud/ucloud-sdk-go/services/udb"
 	"github.com/ucloud/ucloud-sdk-go/services/udisk"
 	"github.com/ucloud/ucloud-sdk-go/services/udpn"
 	"github.com/ucloud/ucloud-sdk-go/services/ufile"
+	"github.com/ucloud/ucloud-sdk-go/services/ufs"
+	"github.com/ucloud/ucloud-sdk-go/services/ugn"
 	"github.com/ucloud/ucloud-sdk-go/services/uhost"
+	"github.com/ucloud/ucloud-sdk-go/services/uk8s"
 	"github.com/ucloud/ucloud-sdk-go/services/ulb"
 	"github.com/ucloud/ucloud-sdk-go/services/umem"
 	"github.com/ucloud/ucloud-sdk-go/services/unet"
@@ -17,6 +26,9 @@ import (
 	"github.com/ucloud/ucloud-sdk-go/ucloud"
 	"github.com/ucloud/ucloud-sdk-go/ucloud/auth"
 	"github.com/ucloud/ucloud-sdk-go/ucloud/log"
+	"math"
+	"net/http"
+	"net/url"
 	"time"
 )
 
@@ -33,35 +45,206 @@ type uCloudClient struct {
 	uphostconn   *uphost.UPHostClient
 	ufileconn    *ufile.UFileClient
 	udiskconn    *udisk.UDiskClient
+	uk8sconn     *uk8s.UK8SClient
+	ufsconn      *ufs.UFSClient
+	ugnconn      *ugn.UGNClient
+	pathxconn    *pathx.PathXClient
+	ucdnconn     *ucdn.UCDNClient
+
+	transport  *http.Transport
+	httpClient *sdkHttpClient
+	configs    map[string]*ucloud.Config
+	credential *auth.Credential
+
+	// maxResources caps the resources fetched by a single discovery
+	maxResources int
 }
 
 type config struct {
 	ProjectId  string
 	PublicKey  string
 	PrivateKey string
+
+	// the endpoint and the transport settings for private or hybrid cloud
+	BaseUrl       string
+	HttpProxy     string
+	TLSCACert     string
+	TLSSkipVerify bool
+
+	// Timeout is the default timeout in seconds, ServiceTimeouts overrides it by the service name like uhost
+	Timeout         int
+	ServiceTimeouts map[string]int
+
+	// MaxResources caps the resources fetched by paging through the Describe APIs
+	MaxResources int
+
+	// CacheTTLs overrides the cache ttl in seconds of the generic api by action, 0 disables the cache
+	CacheTTLs map[string]int
+
+	// RateLimit is the API calls per second, MaxConcurrency caps the API calls in flight and
+	// MaxRetries is the retries of the throttled and transient failures, negative values disable them
+	RateLimit      float64
+	MaxConcurrency int
+	MaxRetries     int
+
+	// StreamInterval is the polling interval in seconds of the live streams
+	StreamInterval int
+}
+
+// defaultServiceTimeouts are the timeouts in seconds of the services with slow APIs.
+var defaultServiceTimeouts = map[string]int{
+	"uhost": 60,
+	"udb":   60,
 }
 
 func getUCloudConfig(instanceSettings backend.DataSourceInstanceSettings) (*config, error) {
-	var setting config
-	jsonData := map[string]interface{}{}
+	var jsonData struct {
+		ProjectId       string         `json:"projectId"`
+		BaseUrl         string         `json:"baseUrl"`
+		HttpProxy       string         `json:"httpProxy"`
+		TLSSkipVerify   bool           `json:"tlsSkipVerify"`
+		Timeout         int            `json:"timeout"`
+		ServiceTimeouts map[string]int `json:"serviceTimeouts"`
+		MaxResources    int            `json:"maxResources"`
+		CacheTTLs       map[string]int `json:"cacheTTLs"`
+		RateLimit       float64        `json:"rateLimit"`
+		MaxConcurrency  int            `json:"maxConcurrency"`
+		MaxRetries      *int           `json:"maxRetries"`
+		StreamInterval  int            `json:"streamInterval"`
+	}
 	if err := json.Unmarshal(instanceSettings.JSONData, &jsonData); err != nil {
 		return nil, err
 	}
 
-	if v, ok := jsonData["projectId"]; ok {
-		setting.ProjectId = v.(string)
+	setting := config{
+		ProjectId:       jsonData.ProjectId,
+		BaseUrl:         jsonData.BaseUrl,
+		HttpProxy:       jsonData.HttpProxy,
+		TLSSkipVerify:   jsonData.TLSSkipVerify,
+		Timeout:         jsonData.Timeout,
+		ServiceTimeouts: jsonData.ServiceTimeouts,
+		MaxResources:    jsonData.MaxResources,
+		CacheTTLs:       jsonData.CacheTTLs,
+		RateLimit:       jsonData.RateLimit,
+		MaxConcurrency:  jsonData.MaxConcurrency,
+		MaxRetries:      defaultMaxRetries,
+		StreamInterval:  jsonData.StreamInterval,
+	}
+	if jsonData.MaxRetries != nil {
+		setting.MaxRetries = *jsonData.MaxRetries
 	}
 	setting.PublicKey = instanceSettings.DecryptedSecureJSONData["publicKey"]
 	setting.PrivateKey = instanceSettings.DecryptedSecureJSONData["privateKey"]
+	setting.TLSCACert = instanceSettings.DecryptedSecureJSONData["tlsCACert"]
 
 	return &setting, nil
 }
 
-func (c *config) Client() *uCloudClient {
+// timeout returns the request timeout of the service.
+func (c *config) timeout(service string) time.Duration {
+	if v, ok := c.ServiceTimeouts[service]; ok && v > 0 {
+		return time.Duration(v) * time.Second
+	}
+	if c.Timeout > 0 {
+		return time.Duration(c.Timeout) * time.Second
+	}
+	if v, ok := defaultServiceTimeouts[service]; ok {
+		return time.Duration(v) * time.Second
+	}
+	return 30 * time.Second
+}
+
+// cacheTTL returns the cache ttl of the generic api action.
+func (c *config) cacheTTL(action string) time.Duration {
+	if v, ok := c.CacheTTLs[action]; ok {
+		return time.Duration(v) * time.Second
+	}
+	return defaultCacheTTLs[action]
+}
+
+// streamInterval returns the polling interval of the live streams.
+func (c *config) streamInterval() time.Duration {
+	if c.StreamInterval > 0 {
+		return time.Duration(c.StreamInterval) * time.Second
+	}
+	return defaultStreamInterval
+}
+
+// newTransport builds the http transport by the proxy and tls settings.
+func (c *config) newTransport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+
+	if c.HttpProxy != "" {
+		proxyUrl, err := url.Parse(c.HttpProxy)
+		if err != nil {
+			return nil, fmt.Errorf("httpProxy is invalid, %s", err)
+		}
+		transport.Proxy = http.ProxyURL(proxyUrl)
+	}
+
+	if c.TLSCACert != "" || c.TLSSkipVerify {
+		tlsConfig := &tls.Config{
+			InsecureSkipVerify: c.TLSSkipVerify,
+		}
+		if c.TLSCACert != "" {
+			pool, err := x509.SystemCertPool()
+			if err != nil {
+				pool = x509.NewCertPool()
+			}
+			if !pool.AppendCertsFromPEM([]byte(c.TLSCACert)) {
+				return nil, fmt.Errorf("tlsCACert is invalid, must set to PEM encoded certificates")
+			}
+			tlsConfig.RootCAs = pool
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return transport, nil
+}
+
+// newHttpClient builds the http client of the SDK with the rate limit, concurrency cap and retries.
+func (c *config) newHttpClient(transport *http.Transport) *sdkHttpClient {
+	httpClient := &sdkHttpClient{
+		client:     &http.Client{Transport: transport},
+		maxRetries: c.MaxRetries,
+	}
+
+	rateLimit := c.RateLimit
+	if rateLimit == 0 {
+		rateLimit = defaultRateLimit
+	}
+	if rateLimit > 0 {
+		httpClient.limiter = newRateLimiter(rateLimit, int(math.Ceil(rateLimit)))
+	}
+
+	maxConcurrency := c.MaxConcurrency
+	if maxConcurrency == 0 {
+		maxConcurrency = defaultMaxConcurrency
+	}
+	if maxConcurrency > 0 {
+		httpClient.sem = make(chan struct{}, maxConcurrency)
+	}
+	return httpClient
+}
+
+// services are the names of the services to set the timeouts, umon is for the generic requests.
+var services = []string{"umon", "unet", "ulb", "vpc", "umem", "udpn", "uaccount", "uphost", "ufile", "udisk", "udb", "uhost", "uk8s", "ufs", "ugn", "pathx", "ucdn"}
+
+func (c *config) Client() (*uCloudClient, error) {
 	var client uCloudClient
 
+	transport, err := c.newTransport()
+	if err != nil {
+		return nil, err
+	}
+	client.transport = transport
+	client.maxResources = c.MaxResources
+	client.httpClient = c.newHttpClient(transport)
+
 	cfg := ucloud.NewConfig()
 	cfg.ProjectId = c.ProjectId
+	if c.BaseUrl != "" {
+		cfg.BaseUrl = c.BaseUrl
+	}
 
 	cfg.LogLevel = log.PanicLevel
 	cfg.UserAgent = "UCloud-monitor-grafana"
@@ -69,21 +252,95 @@ func (c *config) Client() *uCloudClient {
 	cred := auth.NewCredential()
 	cred.PublicKey = c.PublicKey
 	cred.PrivateKey = c.PrivateKey
+	client.credential = &cred
+
+	client.configs = make(map[string]*ucloud.Config, len(services))
+	for _, service := range services {
+		serviceCfg := cfg
+		serviceCfg.Timeout = c.timeout(service)
+		client.configs[service] = &serviceCfg
+	}
+
+	client.connect(client.httpClient)
+	return &client, nil
+}
+
+// connect initializes the client connections which send the requests by the http client.
+func (client *uCloudClient) connect(httpClient *sdkHttpClient) {
+	client.ucloudconn = ucloud.NewClient(client.configs["umon"], client.credential)
+	client.unetconn = unet.NewClient(client.configs["unet"], client.credential)
+	client.ulbconn = ulb.NewClient(client.configs["ulb"], client.credential)
+	client.vpcconn = vpc.NewClient(client.configs["vpc"], client.credential)
+	client.umemconn = umem.NewClient(client.configs["umem"], client.credential)
+	client.udpnconn = udpn.NewClient(client.configs["udpn"], client.credential)
+	client.uaccountconn = uaccount.NewClient(client.configs["uaccount"], client.credential)
+	client.uphostconn = uphost.NewClient(client.configs["uphost"], client.credential)
+	client.ufileconn = ufile.NewClient(client.configs["ufile"], client.credential)
+	client.udiskconn = udisk.NewClient(client.configs["udisk"], client.credential)
+	client.udbconn = udb.NewClient(client.configs["udb"], client.credential)
+	client.uhostconn = uhost.NewClient(client.configs["uhost"], client.credential)
+	client.uk8sconn = uk8s.NewClient(client.configs["uk8s"], client.credential)
+	client.ufsconn = ufs.NewClient(client.configs["ufs"], client.credential)
+	client.ugnconn = ugn.NewClient(client.configs["ugn"], client.credential)
+	client.pathxconn = pathx.NewClient(client.configs["pathx"], client.credential)
+	client.ucdnconn = ucdn.NewClient(client.configs["ucdn"], client.credential)
+
+	for _, conn := range []*ucloud.Client{
+		client.ucloudconn,
+		client.unetconn.Client,
+		client.ulbconn.Client,
+		client.vpcconn.Client,
+		client.umemconn.Client,
+		client.udpnconn.Client,
+		client.uaccountconn.Client,
+		client.uphostconn.Client,
+		client.ufileconn.Client,
+		client.udiskconn.Client,
+		client.udbconn.Client,
+		client.uhostconn.Client,
+		client.uk8sconn.Client,
+		client.ufsconn.Client,
+		client.ugnconn.Client,
+		client.pathxconn.Client,
+		client.ucdnconn.Client,
+	} {
+		_ = conn.SetHttpClient(httpClient)
+	}
+}
+
+// withContext returns a copy of the client whose API calls are aborted when the context is done,
+// the copy shares the transport, rate limiter and concurrency cap with the client. The connections
+// built by connect are copied shallowly, only the http client of the copies is bound to the context.
+func (client *uCloudClient) withContext(ctx context.Context) *uCloudClient {
+	httpClient := client.httpClient.withContext(ctx)
+	bind := func(conn *ucloud.Client) *ucloud.Client {
+		bound := *conn
+		_ = bound.SetHttpClient(httpClient)
+		return &bound
+	}
+
+	c := *client
+	c.ucloudconn = bind(client.ucloudconn)
+	c.unetconn = &unet.UNetClient{Client: bind(client.unetconn.Client)}
+	c.ulbconn = &ulb.ULBClient{Client: bind(client.ulbconn.Client)}
+	c.vpcconn = &vpc.VPCClient{Client: bind(client.vpcconn.Client)}
+	c.umemconn = &umem.UMemClient{Client: bind(client.umemconn.Client)}
+	c.udpnconn = &udpn.UDPNClient{Client: bind(client.udpnconn.Client)}
+	c.uaccountconn = &uaccount.UAccountClient{Client: bind(client.uaccountconn.Client)}
+	c.uphostconn = &uphost.UPHostClient{Client: bind(client.uphostconn.Client)}
+	c.ufileconn = &ufile.UFileClient{Client: bind(client.ufileconn.Client)}
+	c.udiskconn = &udisk.UDiskClient{Client: bind(client.udiskconn.Client)}
+	c.udbconn = &udb.UDBClient{Client: bind(client.udbconn.Client)}
+	c.uhostconn = &uhost.UHostClient{Client: bind(client.uhostconn.Client)}
+	c.uk8sconn = &uk8s.UK8SClient{Client: bind(client.uk8sconn.Client)}
+	c.ufsconn = &ufs.UFSClient{Client: bind(client.ufsconn.Client)}
+	c.ugnconn = &ugn.UGNClient{Client: bind(client.ugnconn.Client)}
+	c.pathxconn = &pathx.PathXClient{Client: bind(client.pathxconn.Client)}
+	c.ucdnconn = &ucdn.UCDNClient{Client: bind(client.ucdnconn.Client)}
+	return &c
+}
 
-	// initialize client connections
-	client.ucloudconn = ucloud.NewClient(&cfg, &cred)
-	client.unetconn = unet.NewClient(&cfg, &cred)
-	client.ulbconn = ulb.NewClient(&cfg, &cred)
-	client.vpcconn = vpc.NewClient(&cfg, &cred)
-	client.umemconn = umem.NewClient(&cfg, &cred)
-	client.udpnconn = udpn.NewClient(&cfg, &cred)
-	client.uaccountconn = uaccount.NewClient(&cfg, &cred)
-	client.uphostconn = uphost.NewClient(&cfg, &cred)
-	client.ufileconn = ufile.NewClient(&cfg, &cred)
-	client.udiskconn = udisk.NewClient(&cfg, &cred)
-	longtimeCfg := cfg
-	longtimeCfg.Timeout = 60 * time.Second
-	client.udbconn = udb.NewClient(&longtimeCfg, &cred)
-	client.uhostconn = uhost.NewClient(&longtimeCfg, &cred)
-	return &client
+// close releases the idle connections of the transport.
+func (client *uCloudClient) close() {
+	client.transport.CloseIdleConnections()
 }
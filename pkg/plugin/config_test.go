@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+func TestGetUCloudConfig(t *testing.T) {
+	conf, err := getUCloudConfig(backend.DataSourceInstanceSettings{
+		JSONData:                []byte(`{"projectId":"org-xxx","baseUrl":"https://api.example.com","timeout":10,"serviceTimeouts":{"udb":120}}`),
+		DecryptedSecureJSONData: map[string]string{"publicKey": "pub", "privateKey": "pri"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if conf.ProjectId != "org-xxx" || conf.BaseUrl != "https://api.example.com" || conf.PublicKey != "pub" {
+		t.Fatalf("unexpected config %+v", conf)
+	}
+
+	for service, want := range map[string]time.Duration{
+		"udb":   120 * time.Second,
+		"uhost": 10 * time.Second,
+		"ulb":   10 * time.Second,
+	} {
+		if got := conf.timeout(service); got != want {
+			t.Errorf("timeout of %s, got %s, want %s", service, got, want)
+		}
+	}
+
+	conf.Timeout = 0
+	if got := conf.timeout("uhost"); got != 60*time.Second {
+		t.Errorf("default timeout of uhost, got %s", got)
+	}
+	if got := conf.timeout("ulb"); got != 30*time.Second {
+		t.Errorf("default timeout of ulb, got %s", got)
+	}
+}
+
+func TestNewTransport(t *testing.T) {
+	conf := &config{HttpProxy: "http://127.0.0.1:8080", TLSSkipVerify: true}
+	transport, err := conf.newTransport()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !transport.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected to skip tls verify")
+	}
+
+	conf = &config{TLSCACert: "invalid"}
+	if _, err := conf.newTransport(); err == nil {
+		t.Error("expected error of invalid ca cert")
+	}
+}
+
+// newTestClient returns the client of the test server replying by the handler, the server is closed
+// when the test finishes.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *uCloudClient {
+	srv := httptest.NewServer(handler)
+	t.Cleanup(srv.Close)
+
+	client, err := (&config{BaseUrl: srv.URL, PublicKey: "pub", PrivateKey: "pri"}).Client()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return client
+}
+
+func TestClientWithContext(t *testing.T) {
+	done := make(chan struct{})
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		<-done
+	})
+	defer close(done)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.withContext(ctx).getMetricData(queryModel{Region: "cn-bj2", ResourceType: "uhost"}, resource{Id: "uhost-xxx"}, 60, 0, 60)
+	if err == nil {
+		t.Fatal("expected error of the cancelled request")
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("expected the request is aborted, got %s", elapsed)
+	}
+}
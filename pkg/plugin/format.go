@@ -0,0 +1,115 @@
+package plugin
+
+import (
+	"fmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"sort"
+	"time"
+)
+
+const (
+	// FormatTimeSeries returns one frame per series, the series are identified by the labels of the value field.
+	FormatTimeSeries = "time_series"
+	// FormatLong returns a single long frame, the labels are the string columns of each row.
+	FormatLong = "long"
+	// FormatTable returns a table of the series ranked by the reduced values.
+	FormatTable = "table"
+)
+
+func checkFormat(format string) error {
+	switch format {
+	case "", FormatTimeSeries, FormatLong, FormatTable:
+		return nil
+	}
+	return fmt.Errorf("format is invalid, must set to %s, %s or %s, got %s", FormatTimeSeries, FormatLong, FormatTable, format)
+}
+
+// fillMissingSeries adds the empty series of the metrics which have no data points of the resources,
+// so every resource is kept as an alert instance and reduced to no data instead of disappearing.
+func fillMissingSeries(series []*metricSeries, resources []resource, metrics []string) []*metricSeries {
+	found := map[string]bool{}
+	for _, s := range series {
+		found[s.Resource.Id+"\x00"+s.Metric] = true
+	}
+	for _, r := range resources {
+		for _, metric := range metrics {
+			if found[r.Id+"\x00"+metric] {
+				continue
+			}
+			series = append(series, &metricSeries{
+				Name:     r.Id,
+				Metric:   metric,
+				Resource: r,
+				Labels:   r.labels(),
+				Times:    []time.Time{},
+				Values:   []float64{},
+			})
+		}
+	}
+	return series
+}
+
+// longFrame returns the series as a single long frame sorted by time, the labels of all the series
+// are the string columns and the values are in the value column. The empty series are kept as a row
+// of null value at the end of the time range, so the resources without data are not lost.
+func longFrame(series []*metricSeries, end time.Time) *data.Frame {
+	keySet := map[string]bool{}
+	metrics := map[string]bool{}
+	rows := 0
+	for _, s := range series {
+		for k := range s.labels() {
+			keySet[k] = true
+		}
+		metrics[s.Metric] = true
+		rows += len(s.Times) + 1
+	}
+	keys := make([]string, 0, len(keySet))
+	for k := range keySet {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	type row struct {
+		time   time.Time
+		labels data.Labels
+		value  *float64
+	}
+	all := make([]row, 0, rows)
+	for _, s := range series {
+		labels := s.labels()
+		if len(s.Times) == 0 {
+			all = append(all, row{time: end, labels: labels})
+			continue
+		}
+		for i, t := range s.Times {
+			all = append(all, row{time: t, labels: labels, value: &s.Values[i]})
+		}
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].time.Before(all[j].time) })
+
+	times := make([]time.Time, len(all))
+	values := make([]*float64, len(all))
+	columns := make([][]string, len(keys))
+	for j := range keys {
+		columns[j] = make([]string, len(all))
+	}
+	for i, r := range all {
+		times[i] = r.time
+		values[i] = r.value
+		for j, k := range keys {
+			columns[j][i] = r.labels[k]
+		}
+	}
+
+	frame := data.NewFrame("", data.NewField("time", nil, times))
+	for j, k := range keys {
+		frame.Fields = append(frame.Fields, data.NewField(k, nil, columns[j]))
+	}
+	value := data.NewField("value", nil, values)
+	// the unit is only meaningful if all the rows are of the same metric
+	if len(metrics) == 1 && len(series) > 0 {
+		value.SetConfig(series[0].Info.fieldConfig())
+	}
+	frame.Fields = append(frame.Fields, value)
+	return frame
+}
@@ -0,0 +1,54 @@
+package plugin
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"testing"
+	"time"
+)
+
+func TestFillMissingSeries(t *testing.T) {
+	resources := []resource{{Id: "uhost-a", Region: "cn-bj2"}, {Id: "uhost-b", Region: "cn-bj2"}}
+	series := []*metricSeries{
+		{Name: "uhost-a", Metric: "CPUUtilization", Resource: resources[0], Labels: resources[0].labels()},
+	}
+
+	series = fillMissingSeries(series, resources, []string{"CPUUtilization"})
+	if len(series) != 2 || series[1].Resource.Id != "uhost-b" || len(series[1].Times) != 0 {
+		t.Fatalf("unexpected series %+v", series)
+	}
+	labels := series[1].frame().Fields[1].Labels
+	if labels["resourceId"] != "uhost-b" || labels["metric"] != "CPUUtilization" || labels["region"] != "cn-bj2" {
+		t.Errorf("unexpected labels %v", labels)
+	}
+}
+
+func TestLongFrame(t *testing.T) {
+	base := time.Unix(1600000000, 0)
+	series := []*metricSeries{
+		{Metric: "CPUUtilization", Labels: data.Labels{"resourceId": "uhost-a"}, Times: []time.Time{base, base.Add(time.Minute)}, Values: []float64{1, 2}},
+		{Metric: "CPUUtilization", Labels: data.Labels{"resourceId": "uhost-b", "tag": "Default"}, Times: []time.Time{base}, Values: []float64{3}},
+		{Metric: "CPUUtilization", Labels: data.Labels{"resourceId": "uhost-c"}, Times: []time.Time{}, Values: []float64{}},
+	}
+
+	frame := longFrame(series, base.Add(time.Hour))
+	if frame.TimeSeriesSchema().Type != data.TimeSeriesTypeLong {
+		t.Fatalf("expected long frame, got %s", frame.TimeSeriesSchema().Type)
+	}
+	if frame.Rows() != 4 {
+		t.Fatalf("expected 4 rows, got %d", frame.Rows())
+	}
+	// the rows are sorted by time and the missing labels are empty
+	if v, _ := frame.ConcreteAt(4, 1); v != 3.0 {
+		t.Errorf("unexpected value %v", v)
+	}
+	if v, _ := frame.ConcreteAt(3, 0); v != "" {
+		t.Errorf("unexpected tag %v", v)
+	}
+	// the empty series is a null row at the end of the time range
+	if v := frame.At(4, 3).(*float64); v != nil || frame.At(0, 3) != base.Add(time.Hour) || frame.At(2, 3) != "uhost-c" {
+		t.Errorf("expected the null row of uhost-c, got %v", v)
+	}
+	if wide, err := data.LongToWide(frame, nil); err != nil || len(wide.Fields) != 4 {
+		t.Errorf("long to wide got error %v", err)
+	}
+}
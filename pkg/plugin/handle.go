@@ -4,10 +4,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
-	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
+	"github.com/ucloud/ucloud-sdk-go/services/uk8s"
 	"github.com/ucloud/ucloud-sdk-go/ucloud"
 	"net/http"
 	"strconv"
+	"strings"
 )
 
 const (
@@ -29,13 +30,20 @@ const (
 	ResourceTypeUDiskRSSD  = "udisk_rssd"
 	ResourceTypeUDiskSys   = "udisk_sys"
 
-	//todo
-	//ResourceTypeUHadoopHost = "uhadoop_host"
-	//ResourceTypeUKafkaHost  = "ukafka_host"
-	//ResourceTypeUdwNode     = "udw_node"
-	//ResourceTypeUHadoop     = "uhadoop"
-	//ResourceTypeUKafka      = "ukafka"
-	//ResourceTypeUdw         = "udw"
+	ResourceTypeUHadoopHost = "uhadoop_host"
+	ResourceTypeUKafkaHost  = "ukafka_host"
+	ResourceTypeUdwNode     = "udw_node"
+	ResourceTypeUHadoop     = "uhadoop"
+	ResourceTypeUKafka      = "ukafka"
+	ResourceTypeUdw         = "udw"
+
+	ResourceTypeUK8S     = "uk8s"
+	ResourceTypeUK8SNode = "uk8s_node"
+	ResourceTypeUFS      = "ufs"
+	ResourceTypeUDNS     = "udns"
+	ResourceTypeUGN      = "ugn"
+	ResourceTypePathX    = "pathx"
+	ResourceTypeUCDN     = "ucdn"
 
 	ActionGetResourceId   = "GetResourceId"
 	ActionGetMetricName   = "GetMetricName"
@@ -44,75 +52,335 @@ const (
 	ActionGetResourceType = "GetResourceType"
 )
 
-type handleFunc func(params map[string]string, rw http.ResponseWriter)
+type handleFunc func(params map[string]string) ([]metricFindValue, error)
+
+// describeFunc describes the resources, truncated is true if there are more resources than the cap of maxResources.
+type describeFunc func(params map[string]string) (resources []resource, truncated bool, err error)
+
+// describePageFunc describes a single page of the resources, count is the number of the resources
+// in the page before filtered, it is used to detect the last page.
+type describePageFunc func(params map[string]string, limit, offset int) (resources []resource, count int, err error)
+
+// metricFindValue is the option of the variable queries, Text is shown in the dropdown and Value is used in the query.
+type metricFindValue struct {
+	Text  string `json:"text"`
+	Value string `json:"value"`
+}
+
+// resource is the common information of a resource returned by the Describe APIs
+type resource struct {
+	Id     string
+	Name   string
+	IP     string
+	Tag    string
+	Zone   string
+	Region string
+
+	// Status and Spec are shown in the resource inventory only, Spec is the size of the resource like 2C4G
+	Status string
+	Spec   string
+}
+
+// metricFindValue returns the option of the resource, the text is the name with the IP and zone if any.
+func (r resource) metricFindValue() metricFindValue {
+	text := r.Name
+	if text == "" {
+		text = r.Id
+	}
+	var extra []string
+	for _, v := range []string{r.IP, r.Zone} {
+		if v != "" {
+			extra = append(extra, v)
+		}
+	}
+	if len(extra) > 0 {
+		text = fmt.Sprintf("%s (%s)", text, strings.Join(extra, ", "))
+	}
+	return metricFindValue{Text: text, Value: r.Id}
+}
+
+// attribute returns the attribute of the resource by name, it is used to group the series.
+func (r resource) attribute(name string) (string, bool) {
+	switch name {
+	case "resourceId":
+		return r.Id, true
+	case "name":
+		return r.Name, true
+	case "ip":
+		return r.IP, true
+	case "tag":
+		return r.Tag, true
+	case "zone":
+		return r.Zone, true
+	case "region":
+		return r.Region, true
+	}
+	return "", false
+}
+
+// headerWarning is the response header of the generic api to report the values are incomplete.
+const headerWarning = "X-UCloud-Warning"
+
+// genericApiResult is the cached result of the generic api.
+type genericApiResult struct {
+	values  []metricFindValue
+	warning string
+}
 
 type GenericApiHandle struct {
 	ActionMap       map[string]handleFunc
-	ResourceTypeMap map[string]handleFunc
+	ResourceTypeMap map[string]describeFunc
 }
 
 func NewGenericApiHandle(client *uCloudClient) *GenericApiHandle {
 	return &GenericApiHandle{
-		ResourceTypeMap: map[string]handleFunc{
-			ResourceTypeUHost:      client.proxyDescribeUHostInstance,
-			ResourceTypeEIP:        client.proxyDescribeEIP,
-			ResourceTypeULB:        client.proxyDescribeULB,
-			ResourceTypeUDB:        client.proxyDescribeUDBInstance,
-			ResourceTypeUMem:       client.proxyDescribeUMem,
-			ResourceTypeUDPN:       client.proxyDescribeUDPN,
-			ResourceTypePHost:      client.proxyDescribePHost,
-			ResourceTypeShareBW:    client.proxyDescribeShareBW,
-			ResourceTypeUMemCache:  client.proxyDescribeUMemCache,
-			ResourceTypeURedis:     client.proxyDescribeURedis,
-			ResourceTypeNatGW:      client.proxyDescribeNatGW,
-			ResourceTypeUFile:      client.proxyDescribeUFile,
-			ResourceTypeULBVServer: client.proxyDescribeULBVServer,
-			ResourceTypeUDisk:      client.proxyDescribeUDisk,
-			ResourceTypeUDiskSSD:   client.proxyDescribeUDiskSSD,
-			ResourceTypeUDiskRSSD:  client.proxyDescribeUDiskRSSD,
-			ResourceTypeUDiskSys:   client.proxyDescribeUDiskSys,
+		ResourceTypeMap: map[string]describeFunc{
+			ResourceTypeUHost:      client.paginate(client.describeUHostInstance),
+			ResourceTypeEIP:        client.paginate(client.describeEIP),
+			ResourceTypeULB:        client.paginate(client.describeULB),
+			ResourceTypeUDB:        client.paginate(client.describeUDBInstance),
+			ResourceTypeUMem:       client.paginate(client.describeUMem),
+			ResourceTypeUDPN:       client.paginate(untagged(ResourceTypeUDPN, client.describeUDPN)),
+			ResourceTypePHost:      client.paginate(client.describePHost),
+			ResourceTypeShareBW:    client.paginate(untagged(ResourceTypeShareBW, client.describeShareBW)),
+			ResourceTypeUMemCache:  client.paginate(client.describeUMemCache),
+			ResourceTypeURedis:     client.paginate(client.describeURedis),
+			ResourceTypeNatGW:      client.paginate(client.describeNatGW),
+			ResourceTypeUFile:      client.paginate(client.describeUFile),
+			ResourceTypeULBVServer: client.paginate(untagged(ResourceTypeULBVServer, client.describeULBVServer)),
+			ResourceTypeUDisk:      client.paginate(client.describeUDisk),
+			ResourceTypeUDiskSSD:   client.paginate(client.describeUDiskSSD),
+			ResourceTypeUDiskRSSD:  client.paginate(client.describeUDiskRSSD),
+			ResourceTypeUDiskSys:   client.paginate(client.describeUDiskSys),
+
+			ResourceTypeUHadoopHost: client.paginate(client.describeGeneric(uhadoopSpec)),
+			ResourceTypeUKafkaHost:  client.paginate(client.describeGeneric(ukafkaSpec)),
+			ResourceTypeUdwNode:     client.paginate(client.describeGeneric(udwSpec)),
+			ResourceTypeUHadoop:     client.paginate(client.describeGeneric(uhadoopSpec.resources())),
+			ResourceTypeUKafka:      client.paginate(client.describeGeneric(ukafkaSpec.resources())),
+			ResourceTypeUdw:         client.paginate(client.describeGeneric(udwSpec.resources())),
+
+			ResourceTypeUK8S:     client.paginate(untagged(ResourceTypeUK8S, client.describeUK8SCluster)),
+			ResourceTypeUK8SNode: client.paginate(untagged(ResourceTypeUK8SNode, client.describeUK8SNode)),
+			ResourceTypeUFS:      client.paginate(client.describeUFS),
+			ResourceTypeUDNS:     client.paginate(client.describeGeneric(udnsSpec)),
+			ResourceTypeUGN:      client.paginate(client.describeUGN),
+			ResourceTypePathX:    client.paginate(untagged(ResourceTypePathX, client.describePathX)),
+			ResourceTypeUCDN:     client.paginate(untagged(ResourceTypeUCDN, client.describeUCDN)),
 		},
 		ActionMap: map[string]handleFunc{
-			ActionGetMetricName:   client.proxyDescribeResourceMetric,
-			ActionGetProjectId:    client.proxyGetProjectList,
-			ActionGetRegion:       client.proxyGetRegion,
-			ActionGetResourceType: client.proxyResourceType,
+			ActionGetMetricName:   client.describeResourceMetric,
+			ActionGetProjectId:    client.getProjectList,
+			ActionGetRegion:       client.getRegion,
+			ActionGetResourceType: client.listResourceType,
 		},
 	}
 }
 
-func GenericApi(rw http.ResponseWriter, req *http.Request) {
+// untagged wraps the page function of the resource type whose Describe API returns no tags, it fails on
+// the Tag filter instead of returning all the resources of the type.
+func untagged(resourceType string, describe describePageFunc) describePageFunc {
+	return func(params map[string]string, limit, offset int) ([]resource, int, error) {
+		if params["Tag"] != "" {
+			return nil, 0, newBadRequestError("the Tag filter is not supported by the resource type %s", resourceType)
+		}
+		return describe(params, limit, offset)
+	}
+}
+
+const (
+	// describePageSize is the Limit of each page when paging through the Describe APIs.
+	describePageSize = 100
+	// defaultMaxResources caps the resources fetched by a single discovery if not set in the settings.
+	defaultMaxResources = 1000
+)
+
+// paginate pages through the whole result set of the Describe API until the last page or the
+// cap of maxResources. Limit and Offset of the params are the explicit opt-in of a single page.
+func (client *uCloudClient) paginate(describe describePageFunc) describeFunc {
+	return func(params map[string]string) ([]resource, bool, error) {
+		if params["Limit"] != "" || params["Offset"] != "" {
+			limit, offset := 20, 0
+			if v := params["Limit"]; v != "" {
+				var err error
+				if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+					return nil, false, newBadRequestError("type is invalid, Limit must set to positive int value")
+				}
+			}
+			if v := params["Offset"]; v != "" {
+				var err error
+				if offset, err = strconv.Atoi(v); err != nil {
+					return nil, false, newBadRequestError("type is invalid, Offset must set to int value")
+				}
+			}
+			resources, _, err := describe(params, limit, offset)
+			return resources, false, err
+		}
+
+		maxResources := client.maxResources
+		if maxResources <= 0 {
+			maxResources = defaultMaxResources
+		}
+		truncate := func(result []resource) ([]resource, bool, error) {
+			log.DefaultLogger.Warn("the resources are truncated by the cap of maxResources", "maxResources", maxResources, "resourceType", params["ResourceType"])
+			if len(result) > maxResources {
+				result = result[:maxResources]
+			}
+			return result, true, nil
+		}
+
+		wanted := filterIds(params)
+		var result []resource
+		total, more := 0, true
+		for offset := 0; offset < maxResources; offset += describePageSize {
+			resources, count, err := describe(params, describePageSize, offset)
+			if err != nil {
+				return nil, false, err
+			}
+			result = append(result, resources...)
+			total += count
+			// the count of the nested resources like the nodes of the clusters is less than the resources
+			if len(result) > maxResources {
+				return truncate(result)
+			}
+			if count < describePageSize {
+				more = false
+				break
+			}
+			// the pages after all the wanted resources are not needed
+			if len(wanted) > 0 && containsAllIds(result, wanted) {
+				return result, false, nil
+			}
+		}
+
+		// the pages end exactly at the cap, probe the next page to tell whether anything is left
+		if more && total <= maxResources {
+			_, count, err := describe(params, 1, total)
+			if err != nil {
+				return nil, false, err
+			}
+			more = count > 0
+		}
+		if !more && total <= maxResources {
+			return result, false, nil
+		}
+		return truncate(result)
+	}
+}
+
+// paramResourceIds is the comma separated resource ids to filter the Describe APIs by on the server,
+// the APIs without the filter ignore it and the paging stops once all the ids are found.
+const paramResourceIds = "ResourceIds"
+
+// filterIds returns the resource ids to filter by, nil if not set.
+func filterIds(params map[string]string) []string {
+	if params[paramResourceIds] == "" {
+		return nil
+	}
+	return strings.Split(params[paramResourceIds], ",")
+}
+
+// filterId returns the resource id to filter by the APIs which accept a single id, false unless
+// exactly one id is set.
+func filterId(params map[string]string) (string, bool) {
+	ids := filterIds(params)
+	if len(ids) != 1 {
+		return "", false
+	}
+	return ids[0], true
+}
+
+func containsAllIds(resources []resource, ids []string) bool {
+	found := make(map[string]bool, len(resources))
+	for _, r := range resources {
+		found[r.Id] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return false
+		}
+	}
+	return true
+}
+
+// truncatedMessage is the warning of the resources truncated by the cap of maxResources.
+func (client *uCloudClient) truncatedMessage(resourceType string) string {
+	maxResources := client.maxResources
+	if maxResources <= 0 {
+		maxResources = defaultMaxResources
+	}
+	return fmt.Sprintf("only the first %d resources of %s are listed, raise Max Resources in the datasource settings or narrow down by Tag", maxResources, resourceType)
+}
+
+func (d *UCloudDatasource) GenericApi(rw http.ResponseWriter, req *http.Request) {
 	//parse param map
 	params, err := parseRequestParams(req)
 	if err != nil {
 		handleResponse(rw, nil, err)
 		return
 	}
-	datasourceInstanceSettings := httpadapter.PluginConfigFromContext(req.Context()).DataSourceInstanceSettings
-	conf, err := getUCloudConfig(*datasourceInstanceSettings)
-	if err != nil {
-		handleResponse(rw, nil, fmt.Errorf("get ucloud setting got error, %s", err))
+
+	if d.settingsErr != nil {
+		handleResponse(rw, nil, newBadRequestError("%s", d.settingsErr))
 		return
 	}
-	client := conf.Client()
-	handles := NewGenericApiHandle(client)
+
+	key := cacheKey(params)
+	noCache := params[paramNoCache] == "true" || strings.Contains(req.Header.Get("Cache-Control"), "no-cache")
+	if !noCache {
+		if v, ok := d.apiCache.get(key); ok {
+			result := v.(genericApiResult)
+			body, err := json.Marshal(result.values)
+			handleWarning(rw, result.warning)
+			handleResponse(rw, body, err)
+			return
+		}
+	}
+
+	// the UCloud calls are aborted once the resource request is cancelled
+	handles := NewGenericApiHandle(d.client.withContext(req.Context()))
+
+	var values []metricFindValue
+	var warning string
 	if params["Action"] == ActionGetResourceId {
-		if handle, ok := handles.ResourceTypeMap[params["ResourceType"]]; ok {
-			handle(params, rw)
-		} else {
-			handleResponse(rw, nil, fmt.Errorf("got invalid ResourceType %s", params["ResourceType"]))
+		describe, ok := handles.ResourceTypeMap[params["ResourceType"]]
+		if !ok {
+			handleResponse(rw, nil, newBadRequestError("got invalid ResourceType %s", params["ResourceType"]))
+			return
+		}
+		var resources []resource
+		var truncated bool
+		resources, truncated, err = describe(params)
+		for _, r := range resources {
+			values = append(values, r.metricFindValue())
+		}
+		if truncated {
+			warning = d.client.truncatedMessage(params["ResourceType"])
 		}
 	} else {
-		if handle, ok := handles.ActionMap[params["Action"]]; ok {
-			handle(params, rw)
-		} else {
-			handleResponse(rw, nil, fmt.Errorf("got invalid Action %s", params["Action"]))
+		handle, ok := handles.ActionMap[params["Action"]]
+		if !ok {
+			handleResponse(rw, nil, newBadRequestError("got invalid Action %s", params["Action"]))
+			return
 		}
+		values, err = handle(params)
+	}
+	if err != nil {
+		log.DefaultLogger.Error(err.Error())
+		handleResponse(rw, nil, newAPIError(params["Action"], err))
+		return
 	}
+
+	d.apiCache.set(key, genericApiResult{values: values, warning: warning}, d.cacheTTLs[params["Action"]])
+
+	body, err := json.Marshal(values)
+	handleWarning(rw, warning)
+	log.DefaultLogger.Debug(string(body))
+	handleResponse(rw, body, err)
 }
 
-func (client *uCloudClient) proxyResourceType(params map[string]string, rw http.ResponseWriter) {
-	var ids = []string{
+func (client *uCloudClient) listResourceType(params map[string]string) ([]metricFindValue, error) {
+	var resourceTypes = []string{
 		ResourceTypeUHost,
 		ResourceTypeEIP,
 		ResourceTypeULB,
@@ -130,13 +398,29 @@ func (client *uCloudClient) proxyResourceType(params map[string]string, rw http.
 		ResourceTypeUDiskSSD,
 		ResourceTypeUDiskRSSD,
 		ResourceTypeUDiskSys,
-	}
-	d, err := json.Marshal(ids)
-	log.DefaultLogger.Debug(string(d))
-	handleResponse(rw, d, err)
+		ResourceTypeUHadoopHost,
+		ResourceTypeUKafkaHost,
+		ResourceTypeUdwNode,
+		ResourceTypeUHadoop,
+		ResourceTypeUKafka,
+		ResourceTypeUdw,
+		ResourceTypeUK8S,
+		ResourceTypeUK8SNode,
+		ResourceTypeUFS,
+		ResourceTypeUDNS,
+		ResourceTypeUGN,
+		ResourceTypePathX,
+		ResourceTypeUCDN,
+	}
+
+	var values []metricFindValue
+	for _, resourceType := range resourceTypes {
+		values = append(values, metricFindValue{Text: resourceType, Value: resourceType})
+	}
+	return values, nil
 }
 
-func (client *uCloudClient) proxyDescribeULBVServer(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeULBVServer(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.ulbconn.NewDescribeVServerRequest()
 	if v, ok := params["ProjectId"]; ok {
 		request.ProjectId = ucloud.String(v)
@@ -147,50 +431,27 @@ func (client *uCloudClient) proxyDescribeULBVServer(params map[string]string, rw
 	if v, ok := params["ULBId"]; ok {
 		request.ULBId = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
-	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.ulbconn.DescribeVServer(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			// todo
-			//if tag, ok := params["Tag"]; ok {
-			//	if instance.Tag != tag {
-			//		continue
-			//	}
-			//}
-			ids = append(ids, instance.VServerId)
-		}
+		return nil, 0, err
+	}
 
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+	var resources []resource
+	for _, instance := range response.DataSet {
+		resources = append(resources, resource{
+			Id:     instance.VServerId,
+			Name:   instance.VServerName,
+			Status: vServerStatus(instance.Status),
+			Spec:   fmt.Sprintf("%s:%d", instance.Protocol, instance.FrontendPort),
+		})
 	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeUDisk(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUDisk(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.udiskconn.NewDescribeUDiskRequest()
 	request.DiskType = ucloud.String("DataDisk")
 	if v, ok := params["ProjectId"]; ok {
@@ -199,49 +460,37 @@ func (client *uCloudClient) proxyDescribeUDisk(params map[string]string, rw http
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.UDiskId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.udiskconn.DescribeUDisk(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.UDiskId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
-	}
+		resources = append(resources, resource{
+			Id:     instance.UDiskId,
+			Name:   instance.Name,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.Status,
+			Spec:   fmt.Sprintf("%dGB", instance.Size),
+		})
+	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeUDiskSSD(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUDiskSSD(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.udiskconn.NewDescribeUDiskRequest()
 	request.ProtocolVersion = ucloud.Int(1)
 	request.IsBoot = ucloud.String("False")
@@ -252,48 +501,36 @@ func (client *uCloudClient) proxyDescribeUDiskSSD(params map[string]string, rw h
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.UDiskId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.udiskconn.DescribeUDisk(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.UDiskId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
-	}
+		resources = append(resources, resource{
+			Id:     instance.UDiskId,
+			Name:   instance.Name,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.Status,
+			Spec:   fmt.Sprintf("%dGB", instance.Size),
+		})
+	}
+	return resources, len(response.DataSet), nil
 }
-func (client *uCloudClient) proxyDescribeUDiskRSSD(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUDiskRSSD(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.udiskconn.NewDescribeUDiskRequest()
 	request.ProtocolVersion = ucloud.Int(1)
 	request.IsBoot = ucloud.String("False")
@@ -304,49 +541,37 @@ func (client *uCloudClient) proxyDescribeUDiskRSSD(params map[string]string, rw
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.UDiskId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.udiskconn.DescribeUDisk(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.UDiskId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
-	}
+		resources = append(resources, resource{
+			Id:     instance.UDiskId,
+			Name:   instance.Name,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.Status,
+			Spec:   fmt.Sprintf("%dGB", instance.Size),
+		})
+	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeUDiskSys(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUDiskSys(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.udiskconn.NewDescribeUDiskRequest()
 	if v, ok := params["ProjectId"]; ok {
 		request.ProjectId = ucloud.String(v)
@@ -354,53 +579,41 @@ func (client *uCloudClient) proxyDescribeUDiskSys(params map[string]string, rw h
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.UDiskId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.udiskconn.DescribeUDisk(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
-			}
-			if instance.IsBoot != "True" {
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
 				continue
 			}
-
-			ids = append(ids, instance.UDiskId)
+		}
+		if instance.IsBoot != "True" {
+			continue
 		}
 
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+		resources = append(resources, resource{
+			Id:     instance.UDiskId,
+			Name:   instance.Name,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.Status,
+			Spec:   fmt.Sprintf("%dGB", instance.Size),
+		})
 	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeURedis(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeURedis(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.umemconn.NewDescribeURedisGroupRequest()
 	if v, ok := params["ProjectId"]; ok {
 		request.ProjectId = ucloud.String(v)
@@ -408,49 +621,37 @@ func (client *uCloudClient) proxyDescribeURedis(params map[string]string, rw htt
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.GroupId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.umemconn.DescribeURedisGroup(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.GroupId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
-	}
+		resources = append(resources, resource{
+			Id:     instance.GroupId,
+			Name:   instance.Name,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.State,
+			Spec:   fmt.Sprintf("%dGB", instance.Size),
+		})
+	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeNatGW(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeNatGW(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.vpcconn.NewDescribeNATGWRequest()
 	if v, ok := params["ProjectId"]; ok {
 		request.ProjectId = ucloud.String(v)
@@ -458,96 +659,53 @@ func (client *uCloudClient) proxyDescribeNatGW(params map[string]string, rw http
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
-	}
+	request.NATGWIds = filterIds(params)
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.vpcconn.DescribeNATGW(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.NATGWId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+		resources = append(resources, resource{Id: instance.NATGWId, Name: instance.NATGWName, Tag: instance.Tag})
 	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeUFile(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUFile(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.ufileconn.NewDescribeBucketRequest()
 	if v, ok := params["ProjectId"]; ok {
 		request.ProjectId = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
-	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.ufileconn.DescribeBucket(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.BucketId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+		resources = append(resources, resource{Id: instance.BucketId, Name: instance.BucketName, Tag: instance.Tag, Spec: instance.Type})
 	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeUMemCache(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUMemCache(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.umemconn.NewDescribeUMemcacheGroupRequest()
 	if v, ok := params["ProjectId"]; ok {
 		request.ProjectId = ucloud.String(v)
@@ -555,49 +713,36 @@ func (client *uCloudClient) proxyDescribeUMemCache(params map[string]string, rw
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.GroupId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.umemconn.DescribeUMemcacheGroup(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.GroupId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
-	}
+		resources = append(resources, resource{
+			Id:     instance.GroupId,
+			Name:   instance.Name,
+			Tag:    instance.Tag,
+			Status: instance.State,
+			Spec:   fmt.Sprintf("%dGB", instance.Size),
+		})
+	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeShareBW(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeShareBW(params map[string]string, limit, offset int) ([]resource, int, error) {
 	req := client.ucloudconn.NewGenericRequest()
 	reqMap := map[string]interface{}{
 		"Action": "DescribeShareBandwidth",
@@ -609,64 +754,47 @@ func (client *uCloudClient) proxyDescribeShareBW(params map[string]string, rw ht
 	if v, ok := params["ProjectId"]; ok {
 		reqMap["ProjectId"] = v
 	}
-
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		reqMap["Limit"] = limit
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		reqMap["Offset"] = offset
+	if ids := filterIds(params); len(ids) > 0 {
+		reqMap["ShareBandwidthIds"] = ids
 	}
+	reqMap["Limit"] = limit
+	reqMap["Offset"] = offset
 
 	err := req.SetPayload(reqMap)
 	if err != nil {
-		handleResponse(rw, nil, fmt.Errorf("set DescribeShareBandwidth requset got err, %s", err))
-		return
+		return nil, 0, fmt.Errorf("set DescribeShareBandwidth requset got err, %s", err)
 	}
 
 	genericResp, err := client.ucloudconn.GenericInvoke(req)
 	if err != nil {
-		handleResponse(rw, nil, fmt.Errorf("do DescribeShareBandwidth got err, %s", err))
-		return
+		return nil, 0, err
 	}
 
 	type DescribeShareBandwidthResponse struct {
 		DataSet []struct {
 			ShareBandwidthId string
+			Name             string
+			ShareBandwidth   int
 		}
 	}
 	respDescribe := &DescribeShareBandwidthResponse{}
 	if err = genericResp.Unmarshal(respDescribe); err != nil {
-		handleResponse(rw, nil, fmt.Errorf("unmarshal DescribeShareBandwidth resp got err, %s", err))
-		return
+		return nil, 0, fmt.Errorf("unmarshal DescribeShareBandwidth resp got err, %w", err)
 	}
 
-	var ids []string
+	var resources []resource
 	for _, instance := range respDescribe.DataSet {
-		// todo
-		//if tag, ok := params["Tag"]; ok {
-		//	if instance.Tag != tag {
-		//		continue
-		//	}
-		//}
-		ids = append(ids, instance.ShareBandwidthId)
-	}
-
-	d, err := json.Marshal(ids)
-	log.DefaultLogger.Debug(string(d))
-	handleResponse(rw, d, err)
+		resources = append(resources, resource{
+			Id:   instance.ShareBandwidthId,
+			Name: instance.Name,
+			Spec: fmt.Sprintf("%dMbps", instance.ShareBandwidth),
+		})
+	}
+
+	return resources, len(respDescribe.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribePHost(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describePHost(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.uphostconn.NewDescribePHostRequest()
 	if v, ok := params["ProjectId"]; ok {
 		request.ProjectId = ucloud.String(v)
@@ -674,150 +802,135 @@ func (client *uCloudClient) proxyDescribePHost(params map[string]string, rw http
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
-	}
+	request.PHostId = filterIds(params)
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.uphostconn.DescribePHost(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.PHostSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.PHostSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.PHostId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
-	}
+		var ip string
+		if len(instance.IPSet) > 0 {
+			ip = instance.IPSet[0].IPAddr
+		}
+		resources = append(resources, resource{
+			Id:     instance.PHostId,
+			Name:   instance.Name,
+			IP:     ip,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.PMStatus,
+			Spec:   instance.PHostType,
+		})
+	}
+	return resources, len(response.PHostSet), nil
 }
 
-func (client *uCloudClient) proxyGetRegion(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) getRegion(params map[string]string) ([]metricFindValue, error) {
 	request := client.uaccountconn.NewGetRegionRequest()
 
 	response, err := client.uaccountconn.GetRegion(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.Regions {
-			var isRepeat bool
-			for _, id := range ids {
-				if instance.Region == id {
-					isRepeat = true
-					break
-				}
-			}
-			if !isRepeat {
-				ids = append(ids, instance.Region)
+		return nil, err
+	}
+
+	var values []metricFindValue
+	for _, instance := range response.Regions {
+		var isRepeat bool
+		for _, v := range values {
+			if instance.Region == v.Value {
+				isRepeat = true
+				break
 			}
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+		if !isRepeat {
+			values = append(values, metricFindValue{Text: instance.Region, Value: instance.Region})
+		}
 	}
+	return values, nil
 }
 
-func (client *uCloudClient) proxyGetProjectList(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) getProjectList(params map[string]string) ([]metricFindValue, error) {
 	request := client.uaccountconn.NewGetProjectListRequest()
 
 	response, err := client.uaccountconn.GetProjectList(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.ProjectSet {
-			ids = append(ids, instance.ProjectId)
-		}
+		return nil, err
+	}
 
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+	var values []metricFindValue
+	for _, instance := range response.ProjectSet {
+		text := instance.ProjectId
+		if instance.ProjectName != "" {
+			text = fmt.Sprintf("%s (%s)", instance.ProjectName, instance.ProjectId)
+		}
+		values = append(values, metricFindValue{Text: text, Value: instance.ProjectId})
 	}
+	return values, nil
 }
 
-func (client *uCloudClient) proxyDescribeResourceMetric(params map[string]string, rw http.ResponseWriter) {
-	request := client.ucloudconn.NewGenericRequest()
-
+func (client *uCloudClient) describeResourceMetric(params map[string]string) ([]metricFindValue, error) {
 	var resourceType string
 	if v, ok := params["ResourceType"]; ok {
 		resourceType = v
 	} else {
-		const message = "must set ResourceType"
-		log.DefaultLogger.Error(fmt.Sprintf(message))
-		handleResponse(rw, nil, fmt.Errorf(message))
-		return
+		return nil, newBadRequestError("must set ResourceType")
+	}
+
+	infos, err := client.describeMetricInfos(resourceType)
+	if err != nil {
+		return nil, err
 	}
 
+	var values []metricFindValue
+	for _, info := range infos {
+		text := info.MetricName
+		if info.DisplayName != "" {
+			text = fmt.Sprintf("%s (%s)", info.MetricName, info.DisplayName)
+		}
+		values = append(values, metricFindValue{Text: text, Value: info.MetricName})
+	}
+
+	return values, nil
+}
+
+func (client *uCloudClient) describeMetricInfos(resourceType string) ([]metricInfo, error) {
+	request := client.ucloudconn.NewGenericRequest()
 	err := request.SetPayload(map[string]interface{}{
 		"Action":       "DescribeResourceMetric",
 		"ResourceType": resourceType,
 	})
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-		return
+		return nil, err
 	}
 	resp, err := client.ucloudconn.GenericInvoke(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-		return
+		return nil, err
 	}
 
-	type ResponseItem struct {
-		MetricName string
-	}
 	type DescribeResourceMetricResponse struct {
-		DataSet []ResponseItem
+		DataSet []metricInfo
 	}
 
 	respObj := DescribeResourceMetricResponse{}
 	err = resp.Unmarshal(&respObj)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-		return
+		return nil, err
 	}
 
-	var names []string
-	for _, instance := range respObj.DataSet {
-		names = append(names, instance.MetricName)
-	}
-
-	d, err := json.Marshal(names)
-	log.DefaultLogger.Debug(string(d))
-	handleResponse(rw, d, err)
+	return respObj.DataSet, nil
 }
 
-func (client *uCloudClient) proxyDescribeUHostInstance(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUHostInstance(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.uhostconn.NewDescribeUHostInstanceRequest()
 
 	if v, ok := params["ProjectId"]; ok {
@@ -829,44 +942,35 @@ func (client *uCloudClient) proxyDescribeUHostInstance(params map[string]string,
 	if v, ok := params["Tag"]; ok {
 		request.Tag = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
-	}
+	request.UHostIds = filterIds(params)
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.uhostconn.DescribeUHostInstance(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.UHostSet {
-			ids = append(ids, instance.UHostId)
-		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+		return nil, 0, err
 	}
+
+	var resources []resource
+	for _, instance := range response.UHostSet {
+		var ip string
+		if len(instance.IPSet) > 0 {
+			ip = instance.IPSet[0].IP
+		}
+		resources = append(resources, resource{
+			Id:     instance.UHostId,
+			Name:   instance.Name,
+			IP:     ip,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.State,
+			Spec:   hostSpec(instance.CPU, instance.Memory),
+		})
+	}
+	return resources, len(response.UHostSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeEIP(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeEIP(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.unetconn.NewDescribeEIPRequest()
 
 	if v, ok := params["ProjectId"]; ok {
@@ -875,49 +979,38 @@ func (client *uCloudClient) proxyDescribeEIP(params map[string]string, rw http.R
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
-	}
+	request.EIPIds = filterIds(params)
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.unetconn.DescribeEIP(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.EIPSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.EIPSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.EIPId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
-	}
+		var ip string
+		if len(instance.EIPAddr) > 0 {
+			ip = instance.EIPAddr[0].IP
+		}
+		resources = append(resources, resource{
+			Id:     instance.EIPId,
+			Name:   instance.Name,
+			IP:     ip,
+			Tag:    instance.Tag,
+			Status: instance.Status,
+			Spec:   fmt.Sprintf("%dMbps", instance.Bandwidth),
+		})
+	}
+	return resources, len(response.EIPSet), nil
 }
-func (client *uCloudClient) proxyDescribeULB(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeULB(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.ulbconn.NewDescribeULBSimpleRequest()
 
 	if v, ok := params["ProjectId"]; ok {
@@ -926,48 +1019,33 @@ func (client *uCloudClient) proxyDescribeULB(params map[string]string, rw http.R
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.ULBId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.ulbconn.DescribeULBSimple(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.ULBId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+		ip := instance.PrivateIP
+		if ip == "" && len(instance.IPSet) > 0 {
+			ip = instance.IPSet[0].EIP
+		}
+		resources = append(resources, resource{Id: instance.ULBId, Name: instance.Name, IP: ip, Tag: instance.Tag, Spec: instance.ULBType})
 	}
+	return resources, len(response.DataSet), nil
 }
-func (client *uCloudClient) proxyDescribeUDBInstance(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUDBInstance(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.udbconn.NewDescribeUDBInstanceRequest()
 
 	if v, ok := params["ProjectId"]; ok {
@@ -980,50 +1058,38 @@ func (client *uCloudClient) proxyDescribeUDBInstance(params map[string]string, r
 	if v, ok := params["ClassType"]; ok {
 		request.ClassType = ucloud.String(v)
 	}
-
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.DBId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.udbconn.DescribeUDBInstance(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.DBId)
 		}
-
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
-	}
+		resources = append(resources, resource{
+			Id:     instance.DBId,
+			Name:   instance.Name,
+			IP:     instance.VirtualIP,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.State,
+			Spec:   fmt.Sprintf("%dMB/%dGB", instance.MemoryLimit, instance.DiskSpace),
+		})
+	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeUDPN(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUDPN(params map[string]string, limit, offset int) ([]resource, int, error) {
 	request := client.udpnconn.NewDescribeUDPNRequest()
 
 	if v, ok := params["ProjectId"]; ok {
@@ -1032,44 +1098,25 @@ func (client *uCloudClient) proxyDescribeUDPN(params map[string]string, rw http.
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
-		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
-	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
-		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
+	if v, ok := filterId(params); ok {
+		request.UDPNId = ucloud.String(v)
 	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
 	response, err := client.udpnconn.DescribeUDPN(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			ids = append(ids, instance.UDPNId)
-		}
+		return nil, 0, err
+	}
 
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+	var resources []resource
+	for _, instance := range response.DataSet {
+		resources = append(resources, resource{Id: instance.UDPNId, Spec: fmt.Sprintf("%dMbps", instance.Bandwidth)})
 	}
+	return resources, len(response.DataSet), nil
 }
 
-func (client *uCloudClient) proxyDescribeUMem(params map[string]string, rw http.ResponseWriter) {
+func (client *uCloudClient) describeUMem(params map[string]string, limit, offset int) ([]resource, int, error) {
 	// distributed memcached and distributed redis
 	request := client.umemconn.NewDescribeUMemSpaceRequest()
 
@@ -1079,57 +1126,279 @@ func (client *uCloudClient) proxyDescribeUMem(params map[string]string, rw http.
 	if v, ok := params["Region"]; ok {
 		request.Region = ucloud.String(v)
 	}
+	if v, ok := filterId(params); ok {
+		request.SpaceId = ucloud.String(v)
+	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
 
-	if v, ok := params["Limit"]; ok {
-		limit, err := strconv.Atoi(v)
-		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Limit must set to int value"))
-			return
+	response, err := client.umemconn.DescribeUMemSpace(request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
+			}
 		}
-		request.Limit = ucloud.Int(limit)
-	} else {
-		request.Limit = ucloud.Int(20)
+		resources = append(resources, resource{
+			Id:     instance.SpaceId,
+			Name:   instance.Name,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.State,
+			Spec:   fmt.Sprintf("%dGB", instance.Size),
+		})
+	}
+	return resources, len(response.DataSet), nil
+}
+
+func (client *uCloudClient) describeUK8SCluster(params map[string]string, limit, offset int) ([]resource, int, error) {
+	clusters, err := client.listUK8SCluster(params, limit, offset)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, cluster := range clusters {
+		resources = append(resources, resource{
+			Id:     cluster.ClusterId,
+			Name:   cluster.ClusterName,
+			IP:     cluster.ApiServer,
+			Status: cluster.Status,
+			Spec:   cluster.K8sVersion,
+		})
+	}
+	return resources, len(clusters), nil
+}
+
+// describeUK8SNode lists the nodes of the clusters in the page, only the nodes of the cluster are listed if ClusterId is set.
+func (client *uCloudClient) describeUK8SNode(params map[string]string, limit, offset int) ([]resource, int, error) {
+	clusters, err := client.listUK8SCluster(params, limit, offset)
+	if err != nil {
+		return nil, 0, err
 	}
-	if v, ok := params["Offset"]; ok {
-		offset, err := strconv.Atoi(v)
+
+	var resources []resource
+	for _, cluster := range clusters {
+		if id, ok := params["ClusterId"]; ok && id != cluster.ClusterId {
+			continue
+		}
+
+		request := client.uk8sconn.NewListUK8SClusterNodeV2Request()
+		if v, ok := params["ProjectId"]; ok {
+			request.ProjectId = ucloud.String(v)
+		}
+		if v, ok := params["Region"]; ok {
+			request.Region = ucloud.String(v)
+		}
+		request.ClusterId = ucloud.String(cluster.ClusterId)
+
+		response, err := client.uk8sconn.ListUK8SClusterNodeV2(request)
 		if err != nil {
-			handleResponse(rw, nil, fmt.Errorf("type is invalid, Offset must set to int value"))
-			return
+			return nil, 0, err
+		}
+		for _, node := range response.NodeSet {
+			var ip string
+			if len(node.IPSet) > 0 {
+				ip = node.IPSet[0].IP
+			}
+			resources = append(resources, resource{
+				Id:     node.NodeId,
+				Name:   node.InstanceName,
+				IP:     ip,
+				Zone:   node.Zone,
+				Status: node.NodeStatus,
+				Spec:   hostSpec(node.CPU, node.Memory),
+			})
 		}
-		request.Offset = ucloud.Int(offset)
-	} else {
-		request.Offset = ucloud.Int(0)
 	}
+	return resources, len(clusters), nil
+}
 
-	response, err := client.umemconn.DescribeUMemSpace(request)
+func (client *uCloudClient) listUK8SCluster(params map[string]string, limit, offset int) ([]uk8s.ClusterSet, error) {
+	request := client.uk8sconn.NewListUK8SClusterV2Request()
+
+	if v, ok := params["ProjectId"]; ok {
+		request.ProjectId = ucloud.String(v)
+	}
+	if v, ok := params["Region"]; ok {
+		request.Region = ucloud.String(v)
+	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
+
+	response, err := client.uk8sconn.ListUK8SClusterV2(request)
 	if err != nil {
-		log.DefaultLogger.Error(err.Error())
-		handleResponse(rw, nil, err)
-	} else {
-		var ids []string
-		for _, instance := range response.DataSet {
-			if tag, ok := params["Tag"]; ok {
-				if instance.Tag != tag {
-					continue
-				}
+		return nil, err
+	}
+	return response.ClusterSet, nil
+}
+
+func (client *uCloudClient) describeUFS(params map[string]string, limit, offset int) ([]resource, int, error) {
+	request := client.ufsconn.NewDescribeUFSVolume2Request()
+
+	if v, ok := params["ProjectId"]; ok {
+		request.ProjectId = ucloud.String(v)
+	}
+	if v, ok := params["Region"]; ok {
+		request.Region = ucloud.String(v)
+	}
+	if v, ok := filterId(params); ok {
+		request.VolumeId = ucloud.String(v)
+	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
+
+	response, err := client.ufsconn.DescribeUFSVolume2(request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.DataSet {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
+			}
+		}
+		resources = append(resources, resource{
+			Id:   instance.VolumeId,
+			Name: instance.VolumeName,
+			Tag:  instance.Tag,
+			Spec: fmt.Sprintf("%dGB", instance.Size),
+		})
+	}
+	return resources, len(response.DataSet), nil
+}
+
+// describeUGN lists the inter-region bandwidths of the UGNs in the page, only the bandwidths of the UGN are listed if UGNId is set.
+func (client *uCloudClient) describeUGN(params map[string]string, limit, offset int) ([]resource, int, error) {
+	request := client.ugnconn.NewDescribeUGNRequest()
+
+	if v, ok := params["ProjectId"]; ok {
+		request.ProjectId = ucloud.String(v)
+	}
+	if v, ok := params["UGNId"]; ok {
+		request.UGNIds = []string{v}
+	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
+
+	response, err := client.ugnconn.DescribeUGN(request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.UGNs {
+		if tag, ok := params["Tag"]; ok {
+			if instance.Tag != tag {
+				continue
 			}
-			ids = append(ids, instance.SpaceId)
 		}
+		if len(instance.InterRegionBandwidths) == 0 {
+			continue
+		}
+
+		bwRequest := client.ugnconn.NewDescribeInterRegionBandwidthRequest()
+		if v, ok := params["ProjectId"]; ok {
+			bwRequest.ProjectId = ucloud.String(v)
+		}
+		bwRequest.UGNId = ucloud.String(instance.UGNId)
+		bwRequest.Limit = ucloud.Int(len(instance.InterRegionBandwidths))
+
+		bwResponse, err := client.ugnconn.DescribeInterRegionBandwidth(bwRequest)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, bw := range bwResponse.InterRegionBandwidths {
+			resources = append(resources, resource{
+				Id:   bw.InterRegionBandwidthId,
+				Name: fmt.Sprintf("%s %s-%s", instance.Name, bw.Region0, bw.Region1),
+				Tag:  instance.Tag,
+				Spec: fmt.Sprintf("%dMbps", bw.Bandwidth),
+			})
+		}
+	}
+	return resources, len(response.UGNs), nil
+}
+
+func (client *uCloudClient) describePathX(params map[string]string, limit, offset int) ([]resource, int, error) {
+	request := client.pathxconn.NewDescribeUGAInstanceRequest()
+
+	if v, ok := params["ProjectId"]; ok {
+		request.ProjectId = ucloud.String(v)
+	}
+	request.Limit = ucloud.Int(limit)
+	request.Offset = ucloud.Int(offset)
+
+	response, err := client.pathxconn.DescribeUGAInstance(request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, instance := range response.UGAList {
+		resources = append(resources, resource{Id: instance.UGAId, Name: instance.UGAName, IP: instance.CName})
+	}
+	return resources, len(response.UGAList), nil
+}
+
+// describeUCDN lists the domains of UCDN, the API is paged by the page index which starts from 1.
+func (client *uCloudClient) describeUCDN(params map[string]string, limit, offset int) ([]resource, int, error) {
+	request := client.ucdnconn.NewGetUcdnDomainInfoListRequest()
+
+	if v, ok := params["ProjectId"]; ok {
+		request.ProjectId = ucloud.String(v)
+	}
+	request.PageSize = ucloud.Int(limit)
+	request.PageIndex = ucloud.Int(offset/limit + 1)
+
+	response, err := client.ucdnconn.GetUcdnDomainInfoList(request)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var resources []resource
+	for _, domain := range response.DomainInfoList {
+		resources = append(resources, resource{Id: domain.DomainId, Name: domain.Domain})
+	}
+	return resources, len(response.DomainInfoList), nil
+}
 
-		d, err := json.Marshal(ids)
-		log.DefaultLogger.Debug(string(d))
-		handleResponse(rw, d, err)
+// hostSpec returns the spec of the host like 2C4G, memory is in MB.
+func hostSpec(cpu, memory int) string {
+	return fmt.Sprintf("%dC%dG", cpu, memory/1024)
+}
+
+// vServerStatus returns the status of the VServer, 0 is normal and 1 is abnormal.
+func vServerStatus(status int) string {
+	if status == 0 {
+		return "Normal"
+	}
+	return "Abnormal"
+}
+
+// handleWarning reports the warning of the incomplete values by the header, the values are kept as a list for the variables.
+func handleWarning(rw http.ResponseWriter, warning string) {
+	if warning != "" {
+		rw.Header().Set(headerWarning, warning)
 	}
 }
 
 func handleResponse(rw http.ResponseWriter, data []byte, err error) {
 	if err != nil {
-		rw.Write([]byte(err.Error()))
-		rw.WriteHeader(http.StatusInternalServerError)
-	} else {
-		rw.Header().Add("Content-Type", "application/json")
-		rw.WriteHeader(http.StatusOK)
-		rw.Write(data)
+		writeError(rw, err)
+		return
+	}
+	rw.Header().Add("Content-Type", "application/json")
+	rw.WriteHeader(http.StatusOK)
+	if _, err := rw.Write(data); err != nil {
+		log.DefaultLogger.Error("write response got error", "error", err)
 	}
 }
 
@@ -1143,7 +1412,7 @@ func parseRequestParams(req *http.Request) (map[string]string, error) {
 	log.DefaultLogger.Debug("request_params: ", result)
 	_, hasAction := result["Action"]
 	if !hasAction {
-		return result, fmt.Errorf("missing parameter Action")
+		return result, newBadRequestError("missing parameter Action")
 	}
 	return result, nil
 }
@@ -0,0 +1,251 @@
+package plugin
+
+import (
+	"fmt"
+	"github.com/ucloud/ucloud-sdk-go/ucloud/response"
+)
+
+// genericResourceSpec describes the resources of the products which are not covered by the SDK by the
+// generic requests. The documented fields of each product are decoded into the typed structs by Decode.
+type genericResourceSpec struct {
+	Action string
+	Decode func(resp response.GenericResponse) ([]genericCluster, error)
+	// Nodes lists the nodes of each cluster instead of the clusters
+	Nodes bool
+}
+
+// genericCluster is the resource decoded from the response with its nodes.
+type genericCluster struct {
+	resource
+	Nodes []resource
+}
+
+var (
+	uhadoopSpec = genericResourceSpec{
+		Action: "DescribeUHadoopInstance",
+		Decode: decodeUHadoop,
+		Nodes:  true,
+	}
+
+	ukafkaSpec = genericResourceSpec{
+		Action: "DescribeUKafkaInstance",
+		Decode: decodeUKafka,
+		Nodes:  true,
+	}
+
+	udwSpec = genericResourceSpec{
+		Action: "DescribeUDWInstance",
+		Decode: decodeUDW,
+		Nodes:  true,
+	}
+
+	udnsSpec = genericResourceSpec{
+		Action: "DescribeUDNSZone",
+		Decode: decodeUDNS,
+	}
+)
+
+// decodeUHadoop decodes the clusters of DescribeUHadoopInstance, the nodes are identified by NodeId.
+func decodeUHadoop(resp response.GenericResponse) ([]genericCluster, error) {
+	type DescribeUHadoopInstanceResponse struct {
+		ClusterSet []struct {
+			InstanceId   string
+			InstanceName string
+			Zone         string
+			State        string
+			Tag          string
+			NodeSet      []struct {
+				NodeId   string
+				NodeName string
+				IP       string
+				State    string
+			}
+		}
+	}
+	respDescribe := &DescribeUHadoopInstanceResponse{}
+	if err := resp.Unmarshal(respDescribe); err != nil {
+		return nil, fmt.Errorf("unmarshal DescribeUHadoopInstance resp got err, %s", err)
+	}
+
+	clusters := make([]genericCluster, 0, len(respDescribe.ClusterSet))
+	for _, instance := range respDescribe.ClusterSet {
+		c := genericCluster{resource: resource{
+			Id:     instance.InstanceId,
+			Name:   instance.InstanceName,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.State,
+		}}
+		for _, node := range instance.NodeSet {
+			c.Nodes = append(c.Nodes, resource{Id: node.NodeId, Name: node.NodeName, IP: node.IP, Status: node.State})
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, nil
+}
+
+// decodeUKafka decodes the clusters of DescribeUKafkaInstance, the nodes are identified by NodeInstanceId.
+func decodeUKafka(resp response.GenericResponse) ([]genericCluster, error) {
+	type DescribeUKafkaInstanceResponse struct {
+		ClusterSet []struct {
+			ClusterInstanceId   string
+			ClusterInstanceName string
+			Zone                string
+			State               string
+			Tag                 string
+			NodeSet             []struct {
+				NodeInstanceId string
+				NodeName       string
+				IP             string
+				State          string
+			}
+		}
+	}
+	respDescribe := &DescribeUKafkaInstanceResponse{}
+	if err := resp.Unmarshal(respDescribe); err != nil {
+		return nil, fmt.Errorf("unmarshal DescribeUKafkaInstance resp got err, %s", err)
+	}
+
+	clusters := make([]genericCluster, 0, len(respDescribe.ClusterSet))
+	for _, instance := range respDescribe.ClusterSet {
+		c := genericCluster{resource: resource{
+			Id:     instance.ClusterInstanceId,
+			Name:   instance.ClusterInstanceName,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.State,
+		}}
+		for _, node := range instance.NodeSet {
+			c.Nodes = append(c.Nodes, resource{Id: node.NodeInstanceId, Name: node.NodeName, IP: node.IP, Status: node.State})
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, nil
+}
+
+// decodeUDW decodes the warehouses of DescribeUDWInstance, the nodes are identified by NodeId.
+func decodeUDW(resp response.GenericResponse) ([]genericCluster, error) {
+	type DescribeUDWInstanceResponse struct {
+		DataSet []struct {
+			DWId    string
+			Name    string
+			Zone    string
+			State   string
+			Tag     string
+			NodeSet []struct {
+				NodeId   string
+				NodeName string
+				IP       string
+				State    string
+			}
+		}
+	}
+	respDescribe := &DescribeUDWInstanceResponse{}
+	if err := resp.Unmarshal(respDescribe); err != nil {
+		return nil, fmt.Errorf("unmarshal DescribeUDWInstance resp got err, %s", err)
+	}
+
+	clusters := make([]genericCluster, 0, len(respDescribe.DataSet))
+	for _, instance := range respDescribe.DataSet {
+		c := genericCluster{resource: resource{
+			Id:     instance.DWId,
+			Name:   instance.Name,
+			Tag:    instance.Tag,
+			Zone:   instance.Zone,
+			Status: instance.State,
+		}}
+		for _, node := range instance.NodeSet {
+			c.Nodes = append(c.Nodes, resource{Id: node.NodeId, Name: node.NodeName, IP: node.IP, Status: node.State})
+		}
+		clusters = append(clusters, c)
+	}
+	return clusters, nil
+}
+
+// decodeUDNS decodes the private zones of DescribeUDNSZone.
+func decodeUDNS(resp response.GenericResponse) ([]genericCluster, error) {
+	type DescribeUDNSZoneResponse struct {
+		DNSZoneInfos []struct {
+			DNSZoneId   string
+			DNSZoneName string
+			Tag         string
+		}
+	}
+	respDescribe := &DescribeUDNSZoneResponse{}
+	if err := resp.Unmarshal(respDescribe); err != nil {
+		return nil, fmt.Errorf("unmarshal DescribeUDNSZone resp got err, %s", err)
+	}
+
+	zones := make([]genericCluster, 0, len(respDescribe.DNSZoneInfos))
+	for _, zone := range respDescribe.DNSZoneInfos {
+		zones = append(zones, genericCluster{resource: resource{
+			Id:   zone.DNSZoneId,
+			Name: zone.DNSZoneName,
+			Tag:  zone.Tag,
+		}})
+	}
+	return zones, nil
+}
+
+// resources returns the spec to list the resources without the nodes.
+func (spec genericResourceSpec) resources() genericResourceSpec {
+	spec.Nodes = false
+	return spec
+}
+
+// describeGeneric returns the page function to describe the resources by the spec. The nodes are filtered
+// by the InstanceId param if set, so the variables of the nodes can depend on the variable of the clusters.
+func (client *uCloudClient) describeGeneric(spec genericResourceSpec) describePageFunc {
+	return func(params map[string]string, limit, offset int) ([]resource, int, error) {
+		req := client.ucloudconn.NewGenericRequest()
+		reqMap := map[string]interface{}{
+			"Action": spec.Action,
+			"Limit":  limit,
+			"Offset": offset,
+		}
+		for _, k := range []string{"Region", "Zone", "ProjectId"} {
+			if v := params[k]; v != "" {
+				reqMap[k] = v
+			}
+		}
+		if err := req.SetPayload(reqMap); err != nil {
+			return nil, 0, fmt.Errorf("set %s request got err, %s", spec.Action, err)
+		}
+
+		genericResp, err := client.ucloudconn.GenericInvoke(req)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		clusters, err := spec.Decode(genericResp)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		var resources []resource
+		for _, c := range clusters {
+			if tag, ok := params["Tag"]; ok && c.Tag != tag {
+				continue
+			}
+			if !spec.Nodes {
+				resources = append(resources, c.resource)
+				continue
+			}
+
+			if id, ok := params["InstanceId"]; ok && id != "" && c.Id != id {
+				continue
+			}
+			for _, n := range c.Nodes {
+				if n.Id == "" {
+					continue
+				}
+				if n.Name == "" {
+					n.Name = c.Name
+				}
+				n.Tag, n.Zone = c.Tag, c.Zone
+				resources = append(resources, n)
+			}
+		}
+		return resources, len(clusters), nil
+	}
+}
@@ -0,0 +1,70 @@
+package plugin
+
+import (
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+// the responses of the generic products in the documented fields, the other fields are ignored by the decoding
+var genericResponses = map[string]string{
+	"DescribeUHadoopInstance": `{"Action":"DescribeUHadoopInstanceResponse","RetCode":0,"TotalCount":2,"ClusterSet":[
+		{"InstanceId":"uhadoop-a","InstanceName":"a","Zone":"cn-bj2-02","State":"Running","Tag":"Default","ChargeType":"Month","CreateTime":1600000000,"NodeSet":[
+			{"NodeId":"uhadoop-a-master1","NodeName":"","IP":"10.0.0.1","State":"Running","NodeRole":"master"},
+			{"NodeId":"uhadoop-a-core1","NodeName":"core1","IP":"10.0.0.2","State":"Running","NodeRole":"core"}]},
+		{"InstanceId":"uhadoop-b","InstanceName":"b","Zone":"cn-bj2-03","State":"Running","Tag":"test","NodeSet":[
+			{"NodeId":"uhadoop-b-master1","IP":"10.0.1.1","State":"Running"}]}]}`,
+	"DescribeUKafkaInstance": `{"Action":"DescribeUKafkaInstanceResponse","RetCode":0,"TotalCount":1,"ClusterSet":[
+		{"ClusterInstanceId":"ukafka-a","ClusterInstanceName":"kafka","Zone":"cn-bj2-04","State":"Running","Tag":"Default","KafkaVersion":"2.3.1","NodeSet":[
+			{"NodeInstanceId":"ukafka-a-node1","NodeName":"node1","IP":"10.0.2.1","State":"Running"}]}]}`,
+	"DescribeUDWInstance": `{"Action":"DescribeUDWInstanceResponse","RetCode":0,"TotalCount":1,"DataSet":[
+		{"DWId":"udw-a","Name":"dw","Zone":"cn-bj2-02","State":"Running","Tag":"Default","NodeCount":1,"NodeSet":[
+			{"NodeId":"udw-a-node1","NodeName":"","IP":"10.0.3.1","State":"Running"}]}]}`,
+	"DescribeUDNSZone": `{"Action":"DescribeUDNSZoneResponse","RetCode":0,"TotalCount":1,"DNSZoneInfos":[
+		{"DNSZoneId":"udnszone-a","DNSZoneName":"example.internal","Tag":"Default","Remark":"","IsRecursionEnabled":"enable","VPCInfos":[]}]}`,
+}
+
+func TestDescribeGeneric(t *testing.T) {
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		_, _ = rw.Write([]byte(genericResponses[req.Form.Get("Action")]))
+	})
+
+	for _, c := range []struct {
+		spec     genericResourceSpec
+		params   map[string]string
+		count    int
+		expected []resource
+	}{
+		{uhadoopSpec.resources(), map[string]string{"Tag": "Default"}, 2, []resource{
+			{Id: "uhadoop-a", Name: "a", Tag: "Default", Zone: "cn-bj2-02", Status: "Running"},
+		}},
+		{uhadoopSpec, map[string]string{"InstanceId": "uhadoop-a"}, 2, []resource{
+			{Id: "uhadoop-a-master1", Name: "a", IP: "10.0.0.1", Tag: "Default", Zone: "cn-bj2-02", Status: "Running"},
+			{Id: "uhadoop-a-core1", Name: "core1", IP: "10.0.0.2", Tag: "Default", Zone: "cn-bj2-02", Status: "Running"},
+		}},
+		{ukafkaSpec.resources(), map[string]string{}, 1, []resource{
+			{Id: "ukafka-a", Name: "kafka", Tag: "Default", Zone: "cn-bj2-04", Status: "Running"},
+		}},
+		{ukafkaSpec, map[string]string{}, 1, []resource{
+			{Id: "ukafka-a-node1", Name: "node1", IP: "10.0.2.1", Tag: "Default", Zone: "cn-bj2-04", Status: "Running"},
+		}},
+		{udwSpec.resources(), map[string]string{}, 1, []resource{
+			{Id: "udw-a", Name: "dw", Tag: "Default", Zone: "cn-bj2-02", Status: "Running"},
+		}},
+		{udwSpec, map[string]string{"InstanceId": "udw-a"}, 1, []resource{
+			{Id: "udw-a-node1", Name: "dw", IP: "10.0.3.1", Tag: "Default", Zone: "cn-bj2-02", Status: "Running"},
+		}},
+		{udnsSpec, map[string]string{"Tag": "Default"}, 1, []resource{
+			{Id: "udnszone-a", Name: "example.internal", Tag: "Default"},
+		}},
+	} {
+		resources, count, err := client.describeGeneric(c.spec)(c.params, 100, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if count != c.count || !reflect.DeepEqual(resources, c.expected) {
+			t.Errorf("%s %v, unexpected resources %d %+v", c.spec.Action, c.params, count, resources)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func fakeDescribePage(total int, calls *int) describePageFunc {
+	return func(params map[string]string, limit, offset int) ([]resource, int, error) {
+		*calls++
+		var resources []resource
+		for i := offset; i < offset+limit && i < total; i++ {
+			resources = append(resources, resource{Id: fmt.Sprintf("uhost-%d", i)})
+		}
+		return resources, len(resources), nil
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	client := &uCloudClient{}
+
+	var calls int
+	resources, truncated, err := client.paginate(fakeDescribePage(250, &calls))(map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resources) != 250 || calls != 3 || truncated {
+		t.Fatalf("expected 250 resources in 3 pages, got %d in %d", len(resources), calls)
+	}
+
+	calls = 0
+	client.maxResources = 150
+	resources, truncated, _ = client.paginate(fakeDescribePage(1000, &calls))(map[string]string{})
+	if len(resources) != 150 || calls != 2 || !truncated {
+		t.Fatalf("expected 150 truncated resources in 2 pages, got %d in %d", len(resources), calls)
+	}
+
+	calls = 0
+	client.maxResources = 200
+	resources, truncated, _ = client.paginate(fakeDescribePage(200, &calls))(map[string]string{})
+	if len(resources) != 200 || calls != 3 || truncated {
+		t.Fatalf("expected 200 resources not truncated after the probe, got %d in %d", len(resources), calls)
+	}
+
+	calls = 0
+	resources, truncated, _ = client.paginate(fakeDescribePage(201, &calls))(map[string]string{})
+	if len(resources) != 200 || !truncated {
+		t.Fatalf("expected 200 truncated resources, got %d", len(resources))
+	}
+
+	// each cluster of the page has 10 nodes, the nodes are capped instead of the clusters
+	calls = 0
+	nodes := func(params map[string]string, limit, offset int) ([]resource, int, error) {
+		calls++
+		var resources []resource
+		for i := 0; i < limit*10; i++ {
+			resources = append(resources, resource{Id: fmt.Sprintf("node-%d-%d", offset, i)})
+		}
+		return resources, limit, nil
+	}
+	resources, truncated, _ = client.paginate(nodes)(map[string]string{})
+	if len(resources) != 200 || calls != 1 || !truncated {
+		t.Fatalf("expected 200 truncated nodes in 1 page, got %d in %d", len(resources), calls)
+	}
+
+	calls = 0
+	resources, truncated, _ = client.paginate(fakeDescribePage(1000, &calls))(map[string]string{paramResourceIds: "uhost-5,uhost-120"})
+	if len(resources) != 200 || calls != 2 || truncated {
+		t.Fatalf("expected the paging stopped once the ids are found, got %d in %d", len(resources), calls)
+	}
+
+	calls = 0
+	resources, _, _ = client.paginate(fakeDescribePage(1000, &calls))(map[string]string{"Offset": "10"})
+	if len(resources) != 20 || calls != 1 || resources[0].Id != "uhost-10" {
+		t.Fatalf("expected the single page of explicit Offset, got %d in %d", len(resources), calls)
+	}
+
+	if _, _, err := client.paginate(fakeDescribePage(1, &calls))(map[string]string{"Limit": "x"}); err == nil {
+		t.Fatal("expected error of invalid Limit")
+	}
+}
+
+func TestResourceMetricFindValue(t *testing.T) {
+	for _, c := range []struct {
+		resource resource
+		want     metricFindValue
+	}{
+		{resource{Id: "uhost-1", Name: "web", IP: "10.0.0.1", Zone: "cn-bj2-02"}, metricFindValue{Text: "web (10.0.0.1, cn-bj2-02)", Value: "uhost-1"}},
+		{resource{Id: "eip-1", Name: "gateway", IP: "106.75.0.1"}, metricFindValue{Text: "gateway (106.75.0.1)", Value: "eip-1"}},
+		{resource{Id: "udpn-1"}, metricFindValue{Text: "udpn-1", Value: "udpn-1"}},
+	} {
+		if got := c.resource.metricFindValue(); got != c.want {
+			t.Errorf("got %+v, want %+v", got, c.want)
+		}
+	}
+}
+
+func TestDescribeUK8SNode(t *testing.T) {
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		switch req.Form.Get("Action") {
+		case "ListUK8SClusterV2":
+			_, _ = rw.Write([]byte(`{"RetCode":0,"ClusterSet":[{"ClusterId":"uk8s-a"},{"ClusterId":"uk8s-b"}]}`))
+		case "ListUK8SClusterNodeV2":
+			if id := req.Form.Get("ClusterId"); id != "uk8s-b" {
+				t.Errorf("unexpected cluster %s", id)
+			}
+			_, _ = rw.Write([]byte(`{"RetCode":0,"NodeSet":[{"NodeId":"uk8s-b-node1","InstanceName":"node1","Zone":"cn-bj2-02","NodeStatus":"Running","CPU":2,"Memory":4096,"IPSet":[{"IP":"10.0.0.1"}]}]}`))
+		}
+	})
+	resources, count, err := client.describeUK8SNode(map[string]string{"ClusterId": "uk8s-b"}, 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []resource{{Id: "uk8s-b-node1", Name: "node1", IP: "10.0.0.1", Zone: "cn-bj2-02", Status: "Running", Spec: "2C4G"}}
+	if count != 2 || !reflect.DeepEqual(resources, expected) {
+		t.Errorf("unexpected nodes %d %+v", count, resources)
+	}
+}
+
+func TestUntagged(t *testing.T) {
+	calls := 0
+	describe := untagged(ResourceTypeUDPN, fakeDescribePage(3, &calls))
+	if _, _, err := describe(map[string]string{"Tag": "Default"}, 100, 0); err == nil || calls != 0 {
+		t.Errorf("expected error without calls, got %v and %d calls", err, calls)
+	}
+	if resources, _, err := describe(map[string]string{}, 100, 0); err != nil || len(resources) != 3 {
+		t.Errorf("unexpected resources %v, %v", resources, err)
+	}
+}
+
+func TestDescribeShareBWError(t *testing.T) {
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set(headerRequestUUID, "uuid-1")
+		_, _ = rw.Write([]byte(`{"Action":"DescribeShareBandwidthResponse","RetCode":172,"Message":"No Permission"}`))
+	})
+	_, _, err := client.describeShareBW(map[string]string{}, 100, 0)
+	e := newAPIError(ActionGetResourceId, err)
+	if e.status != http.StatusForbidden || e.RetCode != 172 || e.RequestId != "uuid-1" {
+		t.Errorf("expected the typed error kept, got %+v", e)
+	}
+}
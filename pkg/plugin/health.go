@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	uerr "github.com/ucloud/ucloud-sdk-go/ucloud/error"
+	"net/http"
+	"strings"
+)
+
+// retCodeSignatureError is the RetCode of UCloud API when the signature is invalid.
+const retCodeSignatureError = 171
+
+type healthProject struct {
+	ProjectId   string `json:"projectId"`
+	ProjectName string `json:"projectName"`
+	IsDefault   bool   `json:"isDefault"`
+}
+
+type healthDetails struct {
+	Projects  []healthProject `json:"projects"`
+	Regions   []string        `json:"regions"`
+	Action    string          `json:"action,omitempty"`
+	RetCode   int             `json:"retCode,omitempty"`
+	RequestId string          `json:"requestId,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+// checkCredential calls the cheap authenticated APIs to verify the keys, the project and the permission of UMon.
+func (client *uCloudClient) checkCredential(details *healthDetails) (backend.HealthStatus, string) {
+	projectResp, err := client.uaccountconn.GetProjectList(client.uaccountconn.NewGetProjectListRequest())
+	if err != nil {
+		return details.fail("GetProjectList", err)
+	}
+	for _, project := range projectResp.ProjectSet {
+		details.Projects = append(details.Projects, healthProject{
+			ProjectId:   project.ProjectId,
+			ProjectName: project.ProjectName,
+			IsDefault:   project.IsDefault,
+		})
+	}
+
+	if projectId := client.ucloudconn.GetConfig().ProjectId; projectId != "" {
+		var found bool
+		for _, project := range details.Projects {
+			if project.ProjectId == projectId {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return backend.HealthStatusError, fmt.Sprintf("Project %s is not found or not accessible by the keys", projectId)
+		}
+	}
+
+	regionResp, err := client.uaccountconn.GetRegion(client.uaccountconn.NewGetRegionRequest())
+	if err != nil {
+		return details.fail("GetRegion", err)
+	}
+	for _, region := range regionResp.Regions {
+		if !containsString(details.Regions, region.Region) {
+			details.Regions = append(details.Regions, region.Region)
+		}
+	}
+
+	if _, err = client.describeMetricInfos(ResourceTypeUHost); err != nil {
+		return details.fail("DescribeResourceMetric", err)
+	}
+
+	return backend.HealthStatusOk, fmt.Sprintf("Data source is working, %d projects and %d regions are accessible",
+		len(details.Projects), len(details.Regions))
+}
+
+// fail records the failed action and returns the message by the kind of the error.
+func (details *healthDetails) fail(action string, err error) (backend.HealthStatus, string) {
+	details.Action = action
+	details.Error = err.Error()
+
+	var e uerr.Error
+	if !errors.As(err, &e) {
+		return backend.HealthStatusError, fmt.Sprintf("Do %s got error, %s", action, err)
+	}
+	details.RetCode = e.Code()
+	var ucErr *ucloudError
+	if errors.As(err, &ucErr) {
+		details.RequestId = ucErr.RequestUUID
+	}
+
+	switch {
+	case e.Name() == uerr.ErrNetwork || e.Name() == uerr.ErrSendRequest:
+		return backend.HealthStatusError, fmt.Sprintf("UCloud API endpoint is unreachable, %s", e.Message())
+	case e.Name() == uerr.ErrHTTPStatus:
+		// the endpoint is reachable, the status may be replied by the private endpoint or the proxy
+		switch e.StatusCode() {
+		case http.StatusUnauthorized:
+			return backend.HealthStatusError, fmt.Sprintf("Authentication failed with HTTP status 401, please check the keys and the proxy, %s", e.Message())
+		case http.StatusForbidden:
+			return backend.HealthStatusError, fmt.Sprintf("Access to %s is forbidden with HTTP status 403, %s", action, e.Message())
+		case http.StatusTooManyRequests:
+			return backend.HealthStatusError, fmt.Sprintf("Do %s is rate limited with HTTP status 429, please retry later", action)
+		}
+		return backend.HealthStatusError, fmt.Sprintf("Do %s got HTTP status %d, %s", action, e.StatusCode(), e.Message())
+	case isSignatureError(e):
+		return backend.HealthStatusError, "Signature verification failed, please check the Public Key and Private Key"
+	case isPermissionError(e):
+		return backend.HealthStatusError, fmt.Sprintf("The keys have no permission to %s, %s", action, e.Message())
+	case strings.Contains(strings.ToLower(e.Message()), "project"):
+		return backend.HealthStatusError, fmt.Sprintf("Project is invalid, %s", e.Message())
+	}
+	return backend.HealthStatusError, fmt.Sprintf("Do %s got error, [%d] %s", action, e.Code(), e.Message())
+}
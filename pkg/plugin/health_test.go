@@ -0,0 +1,37 @@
+package plugin
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	uerr "github.com/ucloud/ucloud-sdk-go/ucloud/error"
+)
+
+func TestHealthDetailsFail(t *testing.T) {
+	cases := []struct {
+		err      error
+		expected string
+	}{
+		{uerr.NewServerCodeError(171, "Signature VerifyAC Error"), "Signature verification failed"},
+		{uerr.NewClientError(uerr.ErrNetwork, errors.New("dial tcp: i/o timeout")), "unreachable"},
+		{uerr.NewServerCodeError(230, "Permission Denied"), "no permission"},
+		{uerr.NewServerStatusError(401, "Unauthorized"), "Authentication failed with HTTP status 401"},
+		{uerr.NewServerStatusError(403, "Forbidden"), "forbidden with HTTP status 403"},
+		{uerr.NewServerStatusError(429, "Too Many Requests"), "rate limited"},
+		{uerr.NewServerStatusError(502, "Bad Gateway"), "got HTTP status 502"},
+		{fmt.Errorf("get project got error, %w", uerr.NewServerCodeError(171, "Signature VerifyAC Error")), "Signature verification failed"},
+		{errors.New("unknown"), "Do GetProjectList got error"},
+	}
+	for _, c := range cases {
+		details := &healthDetails{}
+		_, message := details.fail("GetProjectList", c.err)
+		if !strings.Contains(message, c.expected) {
+			t.Errorf("error %s expected message contains %s, got %s", c.err, c.expected, message)
+		}
+		if details.Action != "GetProjectList" {
+			t.Errorf("expected failed action recorded, got %s", details.Action)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package plugin
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+// inventoryColumns are the attributes of the resources shown as the columns of the inventory table.
+var inventoryColumns = []string{"resourceId", "name", "ip", "status", "spec", "tag", "zone", "region"}
+
+// queryResources returns the resources found by the Describe API of the resource type as a table,
+// the resources are filtered by resourceId, tag and resourceName like the metric queries.
+func (d *UCloudDatasource) queryResources(client *uCloudClient, qm queryModel) backend.DataResponse {
+	response := backend.DataResponse{}
+
+	var resources []resource
+	var missing []string
+	var warning string
+	var err error
+	// the inventory is the attributes themselves, the explicit ids are not kept as the bare resources
+	if len(qm.ResourceId) != 0 && !containsString(qm.ResourceId, "*") {
+		resources, missing, warning, err = d.describeExplicitIds(client, qm)
+	} else {
+		resources, warning, err = d.resolveResources(client, qm)
+	}
+	if err != nil {
+		response.Error = fmt.Errorf("get resources of %s got error, %s", qm.ResourceType, panelError(err))
+		return response
+	}
+	if len(missing) > 0 {
+		found := make([]resource, 0, len(resources))
+		for _, r := range resources {
+			if !containsString(missing, r.Id) {
+				found = append(found, r)
+			}
+		}
+		resources = found
+	}
+
+	frame := inventoryFrame(resources)
+	if warning != "" {
+		frame.AppendNotices(data.Notice{Severity: data.NoticeSeverityWarning, Text: warning})
+	}
+	if len(missing) > 0 {
+		frame.AppendNotices(data.Notice{
+			Severity: data.NoticeSeverityWarning,
+			Text:     fmt.Sprintf("the resources %s of %s are not found", strings.Join(missing, ","), qm.ResourceType),
+		})
+	}
+	response.Frames = append(response.Frames, frame)
+	return response
+}
+
+func inventoryFrame(resources []resource) *data.Frame {
+	columns := make([][]string, len(inventoryColumns))
+	for i := range columns {
+		columns[i] = make([]string, 0, len(resources))
+	}
+	for _, r := range resources {
+		values := []string{r.Id, r.Name, r.IP, r.Status, r.Spec, r.Tag, r.Zone, r.Region}
+		for i, v := range values {
+			columns[i] = append(columns[i], v)
+		}
+	}
+
+	frame := data.NewFrame("resources")
+	for i, name := range inventoryColumns {
+		frame.Fields = append(frame.Fields, data.NewField(name, nil, columns[i]))
+	}
+	frame.SetMeta(&data.FrameMeta{PreferredVisualization: data.VisTypeTable})
+	return frame
+}
@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInventoryFrame(t *testing.T) {
+	frame := inventoryFrame([]resource{
+		{Id: "udb-a", Name: "a", IP: "10.0.0.1", Status: "Running", Spec: "2000MB/100GB", Tag: "Default", Zone: "cn-bj2-02", Region: "cn-bj2"},
+		{Id: "udb-b"},
+	})
+	if frame.Rows() != 2 || len(frame.Fields) != len(inventoryColumns) {
+		t.Fatalf("unexpected frame rows %d fields %d", frame.Rows(), len(frame.Fields))
+	}
+	if v, _ := frame.ConcreteAt(3, 0); v != "Running" {
+		t.Errorf("unexpected status %v", v)
+	}
+	if v, _ := frame.ConcreteAt(4, 0); v != "2000MB/100GB" {
+		t.Errorf("unexpected spec %v", v)
+	}
+	if v, _ := frame.ConcreteAt(0, 1); v != "udb-b" {
+		t.Errorf("unexpected resourceId %v", v)
+	}
+}
+
+func TestQueryResourcesExplicitIds(t *testing.T) {
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		switch req.Form.Get("Action") {
+		case "DescribeUHostInstance":
+			_, _ = rw.Write([]byte(`{"RetCode":0,"TotalCount":1,"UHostSet":[{"UHostId":"uhost-2","Name":"db"}]}`))
+		default:
+			_, _ = rw.Write([]byte(`{"RetCode":230,"Message":"Params [Region] not available"}`))
+		}
+	})
+	d := &UCloudDatasource{apiCache: newTTLCache(), cacheTTLs: map[string]time.Duration{}}
+
+	response := d.queryResources(client, queryModel{Region: "cn-bj2", ResourceType: ResourceTypeUHost, ResourceId: stringList{"uhost-3", "uhost-2"}})
+	if response.Error != nil || len(response.Frames) != 1 {
+		t.Fatalf("unexpected response %+v", response)
+	}
+	frame := response.Frames[0]
+	if v, _ := frame.ConcreteAt(0, 0); frame.Rows() != 1 || v != "uhost-2" {
+		t.Errorf("expected only the found resource, got %d rows", frame.Rows())
+	}
+	if frame.Meta == nil || len(frame.Meta.Notices) != 1 || !strings.Contains(frame.Meta.Notices[0].Text, "uhost-3") {
+		t.Errorf("expected the notice of the missing resource, got %+v", frame.Meta)
+	}
+
+	response = d.queryResources(client, queryModel{Region: "cn-bj2", ResourceType: ResourceTypeEIP, ResourceId: stringList{"eip-1"}})
+	if response.Error == nil || !strings.Contains(response.Error.Error(), "not available") {
+		t.Errorf("expected the Describe error returned, got %v", response.Error)
+	}
+}
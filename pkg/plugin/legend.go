@@ -0,0 +1,32 @@
+package plugin
+
+import (
+	"regexp"
+)
+
+var legendPattern = regexp.MustCompile(`\{\{\s*(\w+)\s*\}\}`)
+
+// formatLegend renders the legend template of the series, {{metric}} and {{displayName}} are replaced by
+// the metric name and its display name, the other placeholders like {{name}} are replaced by the series labels or the resource attributes.
+// The unknown placeholders are kept as they are.
+func formatLegend(alias string, s *metricSeries) string {
+	return legendPattern.ReplaceAllStringFunc(alias, func(placeholder string) string {
+		key := legendPattern.FindStringSubmatch(placeholder)[1]
+		switch key {
+		case "metric":
+			return s.Metric
+		case "displayName":
+			if s.Info.DisplayName != "" {
+				return s.Info.DisplayName
+			}
+			return s.Metric
+		}
+		if v, ok := s.Labels[key]; ok {
+			return v
+		}
+		if v, ok := s.Resource.attribute(key); ok {
+			return v
+		}
+		return placeholder
+	})
+}
@@ -0,0 +1,29 @@
+package plugin
+
+import (
+	"testing"
+
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+)
+
+func TestFormatLegend(t *testing.T) {
+	r := resource{Id: "uhost-abc123", Name: "web-1", Region: "cn-bj2"}
+	s := &metricSeries{Metric: "CPUUtilization", Resource: r, Labels: r.labels()}
+	cases := map[string]string{
+		"{{name}} {{metric}}":         "web-1 CPUUtilization",
+		"{{ resourceId }}@{{region}}": "uhost-abc123@cn-bj2",
+		"{{name}} tag={{tag}}":        "web-1 tag=",
+		"{{name}} {{unknown}}":        "web-1 {{unknown}}",
+		"CPU of {{name}}":             "CPU of web-1",
+	}
+	for alias, expected := range cases {
+		if v := formatLegend(alias, s); v != expected {
+			t.Errorf("alias %s expected %s, got %s", alias, expected, v)
+		}
+	}
+
+	aggregated := &metricSeries{Metric: "NetworkOut", Labels: data.Labels{"aggregation": "sum", "tag": "web"}}
+	if v := formatLegend("{{aggregation}} of {{tag}}", aggregated); v != "sum of web" {
+		t.Errorf("expected aggregated legend, got %s", v)
+	}
+}
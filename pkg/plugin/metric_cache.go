@@ -0,0 +1,193 @@
+package plugin
+
+import (
+	"fmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"sort"
+	"time"
+)
+
+const (
+	// metricCacheTTL is how long the series of a metric are kept since it is queried last time.
+	metricCacheTTL = time.Hour
+	// metricSettleDelay is the delay of UMon to collect the data points, the points older than
+	// it are immutable and served from the cache, only the tail after them is fetched again.
+	metricSettleDelay = 5 * time.Minute
+)
+
+type metricPoint struct {
+	Timestamp int64
+	Value     float64
+}
+
+// metricCacheEntry is the cached series of a metric, the points cover [from, settled). window is the
+// widest time range stored into the entry, the entry is trimmed to it so the moving time ranges don't pile up.
+type metricCacheEntry struct {
+	from    int64
+	settled int64
+	window  int64
+	points  []metricPoint
+}
+
+// metricCache caches the GetMetric results keyed on the resource, metric and period.
+// The entries are replaced instead of modified so they are safe to read concurrently.
+type metricCache struct {
+	cache *ttlCache
+}
+
+func newMetricCache() *metricCache {
+	return &metricCache{
+		cache: newTTLCache(),
+	}
+}
+
+func metricCacheKey(qm queryModel, r resource, metric string, period int64) string {
+	return fmt.Sprintf("%s/%s/%s/%s/%s/%d", qm.ProjectId, r.Region, qm.ResourceType, r.Id, metric, period)
+}
+
+// lookup returns the time to fetch the series from and the cached points before it.
+func (c *metricCache) lookup(key string, from, to int64) (int64, []metricPoint) {
+	v, ok := c.cache.get(key)
+	if !ok {
+		return from, nil
+	}
+	entry := v.(*metricCacheEntry)
+	if entry.from > from || entry.settled <= from {
+		return from, nil
+	}
+
+	begin := entry.settled
+	if begin > to {
+		begin = to
+	}
+	var points []metricPoint
+	for _, p := range entry.points {
+		if p.Timestamp >= from && p.Timestamp < begin {
+			points = append(points, p)
+		}
+	}
+	return begin, points
+}
+
+// store merges the settled points of the series covering [from, settled) into the cached entry, so the
+// shorter time ranges like the streams and the other panels don't evict the longer one. The points older
+// than the widest time range stored are dropped.
+func (c *metricCache) store(key string, from, settled, period int64, points []metricPoint) {
+	if settled <= from {
+		return
+	}
+	entry := &metricCacheEntry{from: from, settled: settled, window: settled - from}
+	var merged []metricPoint
+	if v, ok := c.cache.get(key); ok {
+		cached := v.(*metricCacheEntry)
+		// the disjoint ranges are not merged as the points between them are missing
+		if cached.from <= settled && cached.settled >= from {
+			if cached.from < entry.from {
+				entry.from = cached.from
+			}
+			if cached.settled > entry.settled {
+				entry.settled = cached.settled
+			}
+			if cached.window > entry.window {
+				entry.window = cached.window
+			}
+			for _, p := range cached.points {
+				if p.Timestamp < from || p.Timestamp >= settled {
+					merged = append(merged, p)
+				}
+			}
+		}
+	}
+	for _, p := range points {
+		if p.Timestamp >= from && p.Timestamp < settled {
+			merged = append(merged, p)
+		}
+	}
+
+	// keep a margin of two periods as the time ranges are aligned to the period,
+	// the time range just stored is never trimmed
+	begin := entry.settled - entry.window - 2*period
+	if begin > from {
+		begin = from
+	}
+	if entry.from < begin {
+		entry.from = begin
+	}
+	sort.Slice(merged, func(i, j int) bool { return merged[i].Timestamp < merged[j].Timestamp })
+	for _, p := range merged {
+		if p.Timestamp >= entry.from {
+			entry.points = append(entry.points, p)
+		}
+	}
+	c.cache.set(key, entry, metricCacheTTL)
+}
+
+func (c *metricCache) clear() {
+	c.cache.clear()
+}
+
+// getMetric returns one series per metric of the resource, the settled points are served from the
+// cache and only the tail after them is fetched by GetMetric.
+func (d *UCloudDatasource) getMetric(client *uCloudClient, qm queryModel, r resource, period int64, timeRange backend.TimeRange) ([]*metricSeries, error) {
+	// align the time range to the period so the points of the moving time ranges are shared
+	from := timeRange.From.Unix() / period * period
+	to := timeRange.To.Unix()
+	settled := time.Now().Add(-metricSettleDelay).Unix()
+	if settled > to {
+		settled = to
+	}
+	settled = settled / period * period
+
+	begin := to
+	cached := make(map[string][]metricPoint, len(qm.MetricName))
+	for _, metric := range qm.MetricName {
+		b, points := d.metricCache.lookup(metricCacheKey(qm, r, metric, period), from, to)
+		if b < begin {
+			begin = b
+		}
+		cached[metric] = points
+	}
+
+	fetched := map[string][]metricPoint{}
+	if begin < to || begin == from {
+		var err error
+		if fetched, err = client.getMetricData(qm, r, period, begin, to); err != nil {
+			return nil, err
+		}
+	}
+
+	var series []*metricSeries
+	// keep the series in the same order as the requested metrics
+	for _, metric := range qm.MetricName {
+		var points []metricPoint
+		for _, p := range cached[metric] {
+			if p.Timestamp < begin {
+				points = append(points, p)
+			}
+		}
+		points = append(points, fetched[metric]...)
+		sort.Slice(points, func(i, j int) bool { return points[i].Timestamp < points[j].Timestamp })
+
+		d.metricCache.store(metricCacheKey(qm, r, metric, period), from, settled, period, points)
+		if len(points) == 0 {
+			if _, ok := fetched[metric]; !ok {
+				continue
+			}
+		}
+
+		s := &metricSeries{
+			Name:     r.Id,
+			Metric:   metric,
+			Resource: r,
+			Labels:   r.labels(),
+			Times:    make([]time.Time, 0, len(points)),
+			Values:   make([]float64, 0, len(points)),
+		}
+		for _, p := range points {
+			s.Times = append(s.Times, time.Unix(p.Timestamp, 0))
+			s.Values = append(s.Values, p.Value)
+		}
+		series = append(series, s)
+	}
+	return series, nil
+}
@@ -0,0 +1,98 @@
+package plugin
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+)
+
+func TestMetricCache(t *testing.T) {
+	c := newMetricCache()
+	points := []metricPoint{{0, 1}, {60, 2}, {120, 3}, {180, 4}}
+
+	// nothing is cached before stored
+	if begin, cached := c.lookup("k", 0, 240); begin != 0 || cached != nil {
+		t.Fatalf("expected to fetch from 0, got %d %v", begin, cached)
+	}
+
+	// the points before settled are cached, the tail is fetched again
+	c.store("k", 0, 120, 60, points)
+	begin, cached := c.lookup("k", 60, 300)
+	if begin != 120 || !reflect.DeepEqual(cached, []metricPoint{{60, 2}}) {
+		t.Fatalf("expected to fetch from 120 with the cached points, got %d %v", begin, cached)
+	}
+
+	// the overlapped time ranges are merged, the shorter one doesn't evict the older points
+	c.store("k", 60, 240, 60, []metricPoint{{0, 1}, {60, 2}, {120, 3}, {180, 4}, {240, 5}})
+	c.store("k", 180, 240, 60, []metricPoint{{180, 4}})
+	begin, cached = c.lookup("k", 0, 300)
+	if begin != 240 || !reflect.DeepEqual(cached, points) {
+		t.Fatalf("expected the points of the merged time ranges, got %d %v", begin, cached)
+	}
+
+	// the entry is trimmed to the widest time range stored with the margin of two periods
+	c.store("k", 240, 420, 60, []metricPoint{{240, 5}, {300, 6}, {360, 7}})
+	if begin, cached := c.lookup("k", 60, 420); begin != 60 || cached != nil {
+		t.Fatalf("expected to fetch the trimmed points again, got %d %v", begin, cached)
+	}
+	if begin, cached := c.lookup("k", 120, 420); begin != 420 || len(cached) != 5 {
+		t.Fatalf("expected the points since 120 cached, got %d %v", begin, cached)
+	}
+
+	// the disjoint time range replaces the entry
+	c.store("k", 600, 660, 60, []metricPoint{{600, 8}})
+	if begin, cached := c.lookup("k", 120, 420); begin != 120 || cached != nil {
+		t.Fatalf("expected the disjoint time range replaced the entry, got %d %v", begin, cached)
+	}
+
+	// the time range before the cached series is fetched entirely
+	if begin, cached := c.lookup("k", -60, 180); begin != -60 || cached != nil {
+		t.Fatalf("expected to fetch from -60, got %d %v", begin, cached)
+	}
+}
+
+func TestGetMetricTailFetch(t *testing.T) {
+	var mu sync.Mutex
+	var begins []int64
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		begin, _ := strconv.ParseInt(req.Form.Get("BeginTime"), 10, 64)
+		end, _ := strconv.ParseInt(req.Form.Get("EndTime"), 10, 64)
+		mu.Lock()
+		begins = append(begins, begin)
+		mu.Unlock()
+
+		var points []string
+		for ts := begin; ts < end; ts += 60 {
+			points = append(points, fmt.Sprintf(`{"Timestamp":%d,"Value":1}`, ts))
+		}
+		_, _ = fmt.Fprintf(rw, `{"RetCode":0,"DataSets":{"CPUUtilization":[%s]}}`, strings.Join(points, ","))
+	})
+	d := &UCloudDatasource{metricCache: newMetricCache()}
+	qm := queryModel{Region: "cn-bj2", ResourceType: "uhost", MetricName: stringList{"CPUUtilization"}}
+	now := time.Now()
+	timeRange := backend.TimeRange{From: now.Add(-time.Hour), To: now}
+
+	for i := 0; i < 2; i++ {
+		series, err := d.getMetric(client, qm, resource{Id: "uhost-xxx", Region: "cn-bj2"}, 60, timeRange)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(series) != 1 || len(series[0].Times) < 60 {
+			t.Fatalf("expected the points of the whole time range, got %+v", series)
+		}
+	}
+
+	from := timeRange.From.Unix() / 60 * 60
+	settled := now.Add(-metricSettleDelay).Unix() / 60 * 60
+	if len(begins) != 2 || begins[0] != from || begins[1] < settled-60 {
+		t.Errorf("expected the tail after %d fetched by the second query, got %v", settled, begins)
+	}
+}
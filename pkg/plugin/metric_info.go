@@ -0,0 +1,89 @@
+package plugin
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"strings"
+	"time"
+)
+
+// metricInfo is the metadata of a metric returned by DescribeResourceMetric.
+type metricInfo struct {
+	MetricName  string
+	DisplayName string
+	Unit        string
+	Type        string
+}
+
+// grafanaUnits maps the units of UMon to the unit ids of Grafana,
+// the other units are displayed as suffix.
+var grafanaUnits = map[string]string{
+	"%":    "percent",
+	"b/s":  "bps",
+	"Kb/s": "Kbits",
+	"Mb/s": "Mbits",
+	"Gb/s": "Gbits",
+	"B/s":  "Bps",
+	"KB/s": "KBs",
+	"MB/s": "MBs",
+	"GB/s": "GBs",
+	"B":    "bytes",
+	"KB":   "kbytes",
+	"MB":   "mbytes",
+	"GB":   "gbytes",
+	"ms":   "ms",
+	"s":    "s",
+	"pps":  "pps",
+	"次/s":  "ops",
+}
+
+// fieldConfig returns the display config of the value field of the metric.
+func (info metricInfo) fieldConfig() *data.FieldConfig {
+	config := &data.FieldConfig{
+		Description: info.DisplayName,
+	}
+	if unit, ok := grafanaUnits[info.Unit]; ok {
+		config.Unit = unit
+	} else if info.Unit != "" {
+		config.Unit = "suffix:" + info.Unit
+	}
+	if t := strings.ToLower(info.Type); t == "int" || t == "integer" {
+		config.SetDecimals(0)
+	}
+	return config
+}
+
+// metricInfoCache caches the metric metadata per resource type.
+type metricInfoCache struct {
+	cache *ttlCache
+	ttl   time.Duration
+}
+
+func newMetricInfoCache(ttl time.Duration) *metricInfoCache {
+	return &metricInfoCache{
+		cache: newTTLCache(),
+		ttl:   ttl,
+	}
+}
+
+// get returns the metric metadata of the resource type by metric name, it calls DescribeResourceMetric if not cached.
+func (c *metricInfoCache) get(client *uCloudClient, resourceType string) (map[string]metricInfo, error) {
+	if v, ok := c.cache.get(resourceType); ok {
+		return v.(map[string]metricInfo), nil
+	}
+
+	list, err := client.describeMetricInfos(resourceType)
+	if err != nil {
+		return nil, err
+	}
+	infos := make(map[string]metricInfo, len(list))
+	for _, info := range list {
+		infos[info.MetricName] = info
+	}
+
+	c.cache.set(resourceType, infos, c.ttl)
+	return infos, nil
+}
+
+func (c *metricInfoCache) clear() {
+	c.cache.clear()
+}
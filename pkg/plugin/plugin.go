@@ -9,8 +9,11 @@ import (
 	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
 	"github.com/grafana/grafana-plugin-sdk-go/backend/resource/httpadapter"
 	"github.com/grafana/grafana-plugin-sdk-go/data"
-	"github.com/ucloud/ucloud-sdk-go/ucloud"
 	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -25,26 +28,74 @@ import (
 // is useful to clean up resources used by previous datasource instance when a new datasource
 // instance created upon datasource settings changed.
 var (
-	_ backend.QueryDataHandler   = (*UCloudDatasource)(nil)
-	_ backend.CheckHealthHandler = (*UCloudDatasource)(nil)
-	//_ backend.StreamHandler         = (*UCloudDatasource)(nil)
-	//_ instancemgmt.InstanceDisposer = (*UCloudDatasource)(nil)
-	_ backend.CallResourceHandler = (*UCloudDatasource)(nil)
+	_ backend.QueryDataHandler      = (*UCloudDatasource)(nil)
+	_ backend.CheckHealthHandler    = (*UCloudDatasource)(nil)
+	_ backend.StreamHandler         = (*UCloudDatasource)(nil)
+	_ instancemgmt.InstanceDisposer = (*UCloudDatasource)(nil)
+	_ backend.CallResourceHandler   = (*UCloudDatasource)(nil)
 )
 
-// NewUCloudDatasource creates a new datasource instance.
-func NewUCloudDatasource(backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+// NewUCloudDatasource creates a new datasource instance, the settings are parsed and
+// the UCloud clients are built once and shared by all the requests of the instance.
+// The instance is built even if the settings are invalid, so the error is reported by CheckHealth.
+func NewUCloudDatasource(settings backend.DataSourceInstanceSettings) (instancemgmt.Instance, error) {
+	d := &UCloudDatasource{
+		metricCache: newMetricCache(),
+		apiCache:    newTTLCache(),
+		cacheTTLs:   map[string]time.Duration{},
+	}
+	conf, err := getUCloudConfig(settings)
+	if err != nil {
+		d.settingsErr = fmt.Errorf("get ucloud setting got error, %s", err)
+	} else if d.client, err = conf.Client(); err != nil {
+		d.settingsErr = fmt.Errorf("build ucloud client got error, %s", err)
+	}
+	if d.settingsErr != nil {
+		// the defaults are used to build the caches of the instance without the client
+		log.DefaultLogger.Error(d.settingsErr.Error())
+		conf = &config{}
+	}
+
+	for action := range defaultCacheTTLs {
+		d.cacheTTLs[action] = conf.cacheTTL(action)
+	}
+	d.metricInfos = newMetricInfoCache(d.cacheTTLs[ActionGetMetricName])
+	d.streams = newStreamManager(conf.streamInterval(), d.fetchStream)
 	mux := http.NewServeMux()
-	mux.HandleFunc("/generic_api", GenericApi)
-	return &UCloudDatasource{
-		callResourceHandler: httpadapter.New(mux),
-	}, nil
+	mux.HandleFunc("/generic_api", d.GenericApi)
+	d.callResourceHandler = httpadapter.New(mux)
+	return d, nil
 }
 
 // UCloudDatasource is an example datasource which can respond to data queries, reports
 // its health and has streaming skills.
 type UCloudDatasource struct {
 	callResourceHandler backend.CallResourceHandler
+	client              *uCloudClient
+	// settingsErr is the error of the invalid settings, the client is nil if it is set
+	settingsErr error
+	metricInfos *metricInfoCache
+	metricCache *metricCache
+
+	// apiCache caches the results of the generic api by the normalized params
+	apiCache  *ttlCache
+	cacheTTLs map[string]time.Duration
+
+	// streams shares the pollers of the live streams
+	streams *streamManager
+}
+
+// Dispose here tells plugin SDK that plugin wants to clean up resources when a new instance
+// created. As soon as datasource settings change detected by SDK old datasource instance will
+// be disposed and a new one will be created using NewUCloudDatasource factory function.
+func (d *UCloudDatasource) Dispose() {
+	d.streams.close()
+	d.metricInfos.clear()
+	d.apiCache.clear()
+	d.metricCache.clear()
+	if d.client != nil {
+		d.client.close()
+	}
 }
 
 func (d *UCloudDatasource) CallResource(ctx context.Context, req *backend.CallResourceRequest, sender backend.CallResourceResponseSender) error {
@@ -61,14 +112,17 @@ func (d *UCloudDatasource) QueryData(ctx context.Context, req *backend.QueryData
 	// create response struct
 	response := backend.NewQueryDataResponse()
 
-	if req.PluginContext.DataSourceInstanceSettings == nil {
-		return nil, fmt.Errorf("data source setting got nil")
-	}
-	conf, err := getUCloudConfig(*req.PluginContext.DataSourceInstanceSettings)
-	if err != nil {
-		return nil, fmt.Errorf("get ucloud setting got error, %s", err)
+	if d.settingsErr != nil {
+		for _, q := range req.Queries {
+			response.Responses[q.RefID] = backend.DataResponse{Error: d.settingsErr}
+		}
+		return response, nil
 	}
-	client := conf.Client()
+
+	// the UCloud calls of all the queries are aborted once the request is cancelled
+	client := d.client.withContext(ctx)
+	// the alert queries keep the series of the resources without data, so they are evaluated as no data
+	alerting := req.Headers["FromAlert"] == "true"
 
 	// loop over queries and execute them individually.
 	var wg sync.WaitGroup
@@ -76,7 +130,7 @@ func (d *UCloudDatasource) QueryData(ctx context.Context, req *backend.QueryData
 	for _, q := range req.Queries {
 		wg.Add(1)
 		go func(q backend.DataQuery) {
-			res := d.query(ctx, client.ucloudconn, q)
+			res := d.query(ctx, client, q, alerting)
 
 			// save the response in a hashmap
 			// based on with RefID as identifier
@@ -92,15 +146,99 @@ func (d *UCloudDatasource) QueryData(ctx context.Context, req *backend.QueryData
 	return response, nil
 }
 
+const (
+	// QueryTypeMetrics queries the metric time series, it is the default query type
+	QueryTypeMetrics = "metrics"
+	// QueryTypeAlarms queries the alarm history as the annotations
+	QueryTypeAlarms = "alarms"
+	// QueryTypeResources queries the resources as the inventory table
+	QueryTypeResources = "resources"
+)
+
 type queryModel struct {
-	ProjectId    string `json:"projectId"`
-	Region       string `json:"region"`
-	ResourceType string `json:"resourceType"`
-	MetricName   string `json:"metricName"`
-	ResourceId   string `json:"resourceId"`
+	// QueryType is metrics, alarms or resources, empty means metrics
+	QueryType    string     `json:"queryType"`
+	ProjectId    string     `json:"projectId"`
+	Region       string     `json:"region"`
+	ResourceType string     `json:"resourceType"`
+	MetricName   stringList `json:"metricName"`
+	ResourceId   stringList `json:"resourceId"`
+	// Period is the sampling interval in seconds or a duration like 5m, empty or auto means derived from the query interval
+	Period string `json:"period"`
+	// Aggregation combines the series of all the resources, supports sum, avg, min, max, count and pNN like p95
+	Aggregation string `json:"aggregation"`
+	// GroupBy splits the aggregated series by the resource attributes tag, zone or region
+	GroupBy stringList `json:"groupBy"`
+	// Alias is the legend template, supports placeholders like {{resourceId}}, {{name}}, {{tag}}, {{metric}} and {{region}}
+	Alias string `json:"alias"`
+	// Format is time_series for one frame per series, long for a single long frame or table for the ranking
+	Format string `json:"format"`
+	// TopN keeps the first n series of each metric ranked by the values reduced by TopReducer like avg,
+	// max or last, TopOrder is top for the highest values or bottom for the lowest, 0 keeps all the series
+	TopN       int    `json:"topN"`
+	TopReducer string `json:"topReducer"`
+	TopOrder   string `json:"topOrder"`
+
+	// the conditions to find the resources dynamically when resourceId is `*` or not set
+	Tag          string `json:"tag"`
+	ResourceName string `json:"resourceName"`
+	ULBId        string `json:"ulbId"`
+	ClassType    string `json:"classType"`
+}
+
+// stringList is a list of values which can be set from a JSON array or from a single string,
+// the string may be a comma separated list or a multi-value template variable like {a,b,c}.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(b []byte) error {
+	var values []string
+	if err := json.Unmarshal(b, &values); err != nil {
+		var value string
+		if err := json.Unmarshal(b, &value); err != nil {
+			return fmt.Errorf("must set to string or string array, got %s", string(b))
+		}
+		values = []string{value}
+	}
+
+	var result []string
+	for _, value := range values {
+		for _, v := range splitValues(value) {
+			if !containsString(result, v) {
+				result = append(result, v)
+			}
+		}
+	}
+	*l = result
+	return nil
+}
+
+func splitValues(s string) []string {
+	s = strings.TrimSpace(s)
+	if strings.HasPrefix(s, "{") && strings.HasSuffix(s, "}") {
+		s = s[1 : len(s)-1]
+	}
+	var values []string
+	for _, v := range strings.Split(s, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
 }
 
-func (d *UCloudDatasource) query(_ context.Context, client *ucloud.Client, query backend.DataQuery) backend.DataResponse {
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// maxGetMetricConcurrency limits the GetMetric calls fanned out concurrently by a single query.
+const maxGetMetricConcurrency = 10
+
+func (d *UCloudDatasource) query(ctx context.Context, client *uCloudClient, query backend.DataQuery, alerting bool) backend.DataResponse {
 	response := backend.DataResponse{}
 
 	// Unmarshal the JSON into our queryModel.
@@ -110,85 +248,443 @@ func (d *UCloudDatasource) query(_ context.Context, client *ucloud.Client, query
 	if response.Error != nil {
 		return response
 	}
-	reqGet := client.NewGenericRequest()
+	switch qm.QueryType {
+	case "", QueryTypeMetrics:
+	case QueryTypeAlarms:
+		return d.queryAlarms(client, qm, query.TimeRange)
+	case QueryTypeResources:
+		return d.queryResources(client, qm)
+	default:
+		response.Error = fmt.Errorf("queryType is invalid, must set to %s, %s or %s, got %s", QueryTypeMetrics, QueryTypeAlarms, QueryTypeResources, qm.QueryType)
+		return response
+	}
+	if len(qm.MetricName) == 0 {
+		response.Error = fmt.Errorf("must set metricName")
+		return response
+	}
+	if len(qm.ResourceId) == 0 && qm.Tag == "" && qm.ResourceName == "" {
+		response.Error = fmt.Errorf("must set resourceId, or set tag or resourceName to find the resources")
+		return response
+	}
+	if response.Error = checkFormat(qm.Format); response.Error != nil {
+		return response
+	}
+	if response.Error = checkTopN(qm); response.Error != nil {
+		return response
+	}
+
+	period, err := getPeriod(qm.Period, query)
+	if err != nil {
+		response.Error = err
+		return response
+	}
+
+	resources, warning, err := d.resolveResources(client, qm)
+	if err != nil {
+		response.Error = fmt.Errorf("get resource id of %s got error, %s", qm.ResourceType, panelError(err))
+		return response
+	}
+
+	// fan out one GetMetric call per resource
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, maxGetMetricConcurrency)
+	results := make([][]*metricSeries, len(resources))
+	errs := make([]error, len(resources))
+	for i, r := range resources {
+		wg.Add(1)
+		go func(i int, r resource) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			results[i], errs[i] = d.getMetric(client, qm, r, period, query.TimeRange)
+		}(i, r)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		response.Error = fmt.Errorf("query of %s is cancelled, %s", qm.ResourceType, ctx.Err())
+		return response
+	}
+
+	// the query fails if all the resources failed, otherwise the failures are reported as the frame notices
+	var series []*metricSeries
+	var notices []data.Notice
+	if warning != "" {
+		notices = append(notices, data.Notice{Severity: data.NoticeSeverityWarning, Text: warning})
+	}
+	var succeeded []resource
+	failed := 0
+	for i, r := range resources {
+		if errs[i] != nil {
+			log.DefaultLogger.Error("get metric got error", "resourceId", r.Id, "error", errs[i])
+			notices = append(notices, data.Notice{
+				Severity: data.NoticeSeverityWarning,
+				Text:     fmt.Sprintf("get metric of %s got error, %s", r.Id, panelError(errs[i])),
+			})
+			failed++
+			continue
+		}
+		series = append(series, results[i]...)
+		succeeded = append(succeeded, r)
+	}
+	if failed > 0 && failed == len(resources) {
+		response.Error = fmt.Errorf("get metric of %s got error, %s", resources[0].Id, panelError(errs[0]))
+		return response
+	}
+
+	if alerting || qm.Format == FormatLong {
+		series = fillMissingSeries(series, succeeded, qm.MetricName)
+	}
+
+	if qm.Aggregation != "" {
+		if series, err = aggregateSeries(series, qm.Aggregation, qm.GroupBy, period); err != nil {
+			response.Error = err
+			return response
+		}
+	}
+
+	var ranked []rankedSeries
+	if qm.TopN > 0 || qm.Format == FormatTable {
+		if ranked, err = rankSeries(series, qm.TopReducer, qm.TopOrder, qm.TopN); err != nil {
+			response.Error = err
+			return response
+		}
+		series = make([]*metricSeries, 0, len(ranked))
+		for _, r := range ranked {
+			series = append(series, r.series)
+		}
+	}
+
+	// the metric metadata is optional, the series are kept without units if DescribeResourceMetric failed
+	infos, err := d.metricInfos.get(client, qm.ResourceType)
+	if err != nil {
+		log.DefaultLogger.Warn("describe resource metric got error", "resourceType", qm.ResourceType, "error", err)
+	}
+	seriesCount := map[string]int{}
+	for _, s := range series {
+		seriesCount[s.Metric]++
+	}
+	for _, s := range series {
+		if info, ok := infos[s.Metric]; ok && qm.Aggregation != "count" {
+			s.Info = info
+		}
+		if qm.Alias != "" {
+			s.Legend = formatLegend(qm.Alias, s)
+		} else if seriesCount[s.Metric] == 1 {
+			s.Legend = s.Info.DisplayName
+		}
+		if qm.Format == "" || qm.Format == FormatTimeSeries {
+			response.Frames = append(response.Frames, s.frame())
+		}
+	}
+	switch qm.Format {
+	case FormatLong:
+		response.Frames = append(response.Frames, longFrame(series, query.TimeRange.To))
+	case FormatTable:
+		response.Frames = append(response.Frames, rankedTable(ranked))
+	}
+
+	if len(notices) > 0 {
+		if len(response.Frames) == 0 {
+			// the alert queries without data return no frames to be evaluated as no data
+			if alerting {
+				return response
+			}
+			response.Frames = append(response.Frames, data.NewFrame(""))
+		}
+		response.Frames[0].AppendNotices(notices...)
+	}
+	return response
+}
+
+// supportedPeriods are the sampling intervals in seconds supported by GetMetric, in ascending order.
+var supportedPeriods = []int64{60, 300, 3600, 86400}
+
+// getPeriod returns the GetMetric period in seconds. The auto period is derived from the query interval
+// and max data points, all the periods are rounded up to the nearest supported period.
+func getPeriod(period string, query backend.DataQuery) (int64, error) {
+	var interval time.Duration
+	switch period {
+	case "", "auto":
+		interval = query.Interval
+		if query.MaxDataPoints > 0 {
+			if v := query.TimeRange.Duration() / time.Duration(query.MaxDataPoints); v > interval {
+				interval = v
+			}
+		}
+	default:
+		if seconds, err := strconv.ParseInt(period, 10, 64); err == nil {
+			interval = time.Duration(seconds) * time.Second
+		} else if interval, err = time.ParseDuration(period); err != nil {
+			return 0, fmt.Errorf("period is invalid, must set to auto, seconds or duration like 5m, got %s", period)
+		}
+	}
+
+	for _, v := range supportedPeriods {
+		if interval <= time.Duration(v)*time.Second {
+			return v, nil
+		}
+	}
+	return supportedPeriods[len(supportedPeriods)-1], nil
+}
+
+// resolveResources returns the resources to query. When resourceId is `*` or not set, the resources
+// of the resource type are found by the Describe API and filtered by the tag and resource name regex.
+// The attributes of the explicit resource ids are also filled by the Describe API. The warning is set
+// if the resources are truncated by the cap of maxResources.
+func (d *UCloudDatasource) resolveResources(client *uCloudClient, qm queryModel) (resources []resource, warning string, err error) {
+	if len(qm.ResourceId) != 0 && !containsString(qm.ResourceId, "*") {
+		resources, _, warning, err = d.describeExplicitIds(client, qm)
+		// the attributes are optional, keep the bare resources if the Describe API failed
+		if err != nil {
+			log.DefaultLogger.Warn("describe resources got error", "resourceType", qm.ResourceType, "error", err)
+			resources = make([]resource, 0, len(qm.ResourceId))
+			for _, id := range qm.ResourceId {
+				resources = append(resources, resource{Id: id, Region: qm.Region})
+			}
+			return resources, "", nil
+		}
+		return resources, warning, nil
+	}
+
+	var nameRegexp *regexp.Regexp
+	if qm.ResourceName != "" {
+		var err error
+		if nameRegexp, err = regexp.Compile(qm.ResourceName); err != nil {
+			return nil, "", fmt.Errorf("resourceName is invalid regex, %s", err)
+		}
+	}
+
+	described, truncated, err := d.describeResources(client, qm, qm.Tag, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if truncated {
+		warning = client.truncatedMessage(qm.ResourceType)
+	}
+
+	for _, r := range described {
+		if nameRegexp != nil && !nameRegexp.MatchString(r.Name) {
+			continue
+		}
+		resources = append(resources, r)
+	}
+	return resources, warning, nil
+}
+
+// describeExplicitIds returns the resources of the explicit resource ids of the query, the attributes are
+// filled by the Describe API. The ids not found are kept as the bare resources and returned as missing.
+func (d *UCloudDatasource) describeExplicitIds(client *uCloudClient, qm queryModel) (resources []resource, missing []string, warning string, err error) {
+	described, truncated, err := d.describeResources(client, qm, "", qm.ResourceId)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	resources = make([]resource, 0, len(qm.ResourceId))
+	for _, id := range qm.ResourceId {
+		r := resource{Id: id, Region: qm.Region}
+		found := false
+		for _, v := range described {
+			if v.Id == id {
+				r, found = v, true
+				break
+			}
+		}
+		if !found {
+			missing = append(missing, id)
+		}
+		resources = append(resources, r)
+	}
+	// the resources not found may be beyond the cap, their labels are missing
+	if truncated && len(missing) > 0 {
+		warning = client.truncatedMessage(qm.ResourceType) + ", the labels of the resources beyond are missing"
+	}
+	return resources, missing, warning, nil
+}
+
+// describedResources is the cached result of describeResources.
+type describedResources struct {
+	resources []resource
+	truncated bool
+	err       error
+}
+
+// describeResources calls the Describe API of the resource type of the query, truncated is true if the
+// resources are more than the cap of maxResources. The resources of the type are cached by the ttl of
+// GetResourceId, so the queries and the alert evaluations of the same resources share one Describe call.
+// The explicit ids are filtered by the API if supported and cached by the fixed ttl of the resource labels,
+// the failures are cached too so a failing type is not described on every refresh.
+func (d *UCloudDatasource) describeResources(client *uCloudClient, qm queryModel, tag string, ids []string) ([]resource, bool, error) {
+	describe, ok := NewGenericApiHandle(client).ResourceTypeMap[qm.ResourceType]
+	if !ok {
+		return nil, false, fmt.Errorf("got invalid ResourceType %s", qm.ResourceType)
+	}
+	params := map[string]string{"ResourceType": qm.ResourceType}
+	for k, v := range map[string]string{
+		"ProjectId": qm.ProjectId,
+		"Region":    qm.Region,
+		"Tag":       tag,
+		"ULBId":     qm.ULBId,
+		"ClassType": qm.ClassType,
+	} {
+		if v != "" {
+			params[k] = v
+		}
+	}
+	ttl := d.cacheTTLs[ActionGetResourceId]
+	if len(ids) > 0 {
+		sorted := append([]string(nil), ids...)
+		sort.Strings(sorted)
+		params[paramResourceIds] = strings.Join(sorted, ",")
+		ttl = resourceLabelsTTL
+	}
+
+	// the key is apart from the keys of the generic api which are prefixed by the Action
+	key := "describeResources\x00" + cacheKey(params)
+	if v, ok := d.apiCache.get(key); ok {
+		described := v.(describedResources)
+		return described.resources, described.truncated, described.err
+	}
+
+	resources, truncated, err := describe(params)
+	if err != nil {
+		if len(ids) > 0 {
+			d.apiCache.set(key, describedResources{err: err}, resourceLabelsFailureTTL)
+		}
+		return nil, false, err
+	}
+	for i := range resources {
+		resources[i].Region = qm.Region
+	}
+	d.apiCache.set(key, describedResources{resources: resources, truncated: truncated}, ttl)
+	return resources, truncated, nil
+}
+
+// getMetricData calls GetMetric of the resource in [begin, end], the points are returned by metric name.
+func (client *uCloudClient) getMetricData(qm queryModel, r resource, period, begin, end int64) (map[string][]metricPoint, error) {
+	reqGet := client.ucloudconn.NewGenericRequest()
 	if qm.ProjectId != "" {
 		_ = reqGet.SetProjectId(qm.ProjectId)
 	}
-	response.Error = reqGet.SetPayload(map[string]interface{}{
+	err := reqGet.SetPayload(map[string]interface{}{
 		"Action":       "GetMetric",
 		"Region":       qm.Region,
 		"ResourceType": qm.ResourceType,
-		"MetricName":   []string{qm.MetricName},
-		"ResourceId":   qm.ResourceId,
-		"BeginTime":    query.TimeRange.From.Unix(),
-		"EndTime":      query.TimeRange.To.Unix(),
+		"MetricName":   []string(qm.MetricName),
+		"ResourceId":   r.Id,
+		"Period":       period,
+		"BeginTime":    begin,
+		"EndTime":      end,
 	})
-
-	if response.Error != nil {
-		return response
+	if err != nil {
+		return nil, err
 	}
-	respGet, err := client.GenericInvoke(reqGet)
+	respGet, err := client.ucloudconn.GenericInvoke(reqGet)
 	if err != nil {
-		response.Error = err
-		return response
+		return nil, err
 	}
 
-	type ResponseItem struct {
-		Timestamp int64
-		Value     float64
-	}
 	type GetMetricResponse struct {
-		DataSets map[string][]ResponseItem
+		DataSets map[string][]metricPoint
 	}
 
 	respGetObj := GetMetricResponse{}
-	response.Error = respGet.Unmarshal(&respGetObj)
-	if response.Error != nil {
-		return response
+	if err = respGet.Unmarshal(&respGetObj); err != nil {
+		return nil, err
 	}
+	return respGetObj.DataSets, nil
+}
 
-	for metric, items := range respGetObj.DataSets {
-		frame := data.NewFrame(qm.ResourceId)
-		times := make([]time.Time, 0)
-		values := make([]float64, 0)
-		for _, v := range items {
-			times = append(times, time.Unix(v.Timestamp, 0))
-			values = append(values, v.Value)
-		}
-		frame.Fields = append(frame.Fields,
-			data.NewField("time", nil, times),
-			data.NewField(metric, nil, values),
-		)
-		response.Frames = append(response.Frames, frame)
+// metricSeries is the time series of a metric, it is labelled by the resource or by the aggregation group.
+type metricSeries struct {
+	Name     string
+	Legend   string
+	Metric   string
+	Info     metricInfo
+	Resource resource
+	Labels   data.Labels
+	Times    []time.Time
+	Values   []float64
+}
+
+func (s *metricSeries) frame() *data.Frame {
+	config := s.Info.fieldConfig()
+	name := s.Name
+	if s.Legend != "" {
+		name = s.Legend
+		config.DisplayNameFromDS = s.Legend
 	}
+	field := data.NewField(s.Metric, s.labels(), s.Values).SetConfig(config)
+	return data.NewFrame(name,
+		data.NewField("time", nil, s.Times),
+		field,
+	)
+}
 
-	return response
+// labels returns the labels of the series with the metric name, the alert instances are identified by them.
+func (s *metricSeries) labels() data.Labels {
+	labels := data.Labels{"metric": s.Metric}
+	for k, v := range s.Labels {
+		labels[k] = v
+	}
+	return labels
+}
+
+// labels returns the non-empty attributes of the resource as the series labels.
+func (r resource) labels() data.Labels {
+	labels := data.Labels{}
+	for _, k := range []string{"resourceId", "name", "tag", "zone", "region"} {
+		if v, _ := r.attribute(k); v != "" {
+			labels[k] = v
+		}
+	}
+	return labels
 }
 
 // CheckHealth handles health checks sent from Grafana to the plugin.
 // The main use case for these health checks is the test button on the
 // datasource configuration page which allows users to verify that
 // a datasource is working as expected.
-func (d *UCloudDatasource) CheckHealth(_ context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
+func (d *UCloudDatasource) CheckHealth(ctx context.Context, req *backend.CheckHealthRequest) (*backend.CheckHealthResult, error) {
 	log.DefaultLogger.Info("CheckHealth called", "request", req)
 
-	var status = backend.HealthStatusOk
-	var message = "Data source is working"
-
 	if req.PluginContext.DataSourceInstanceSettings != nil {
 		setting := req.PluginContext.DataSourceInstanceSettings
+		var missing []string
 		if setting.DecryptedSecureJSONData["publicKey"] == "" {
-			status = backend.HealthStatusError
-			message = "Public Key must be set"
+			missing = append(missing, "Public Key")
 		}
-
 		if setting.DecryptedSecureJSONData["privateKey"] == "" {
-			status = backend.HealthStatusError
-			message = "Private Key must be set"
+			missing = append(missing, "Private Key")
+		}
+		if len(missing) > 0 {
+			return &backend.CheckHealthResult{
+				Status:  backend.HealthStatusError,
+				Message: fmt.Sprintf("%s must be set", strings.Join(missing, " and ")),
+			}, nil
 		}
 	}
+
+	if d.settingsErr != nil {
+		return &backend.CheckHealthResult{
+			Status:  backend.HealthStatusError,
+			Message: d.settingsErr.Error(),
+		}, nil
+	}
+
+	details := &healthDetails{}
+	status, message := d.client.withContext(ctx).checkCredential(details)
+	jsonDetails, err := json.Marshal(details)
+	if err != nil {
+		return nil, err
+	}
 	return &backend.CheckHealthResult{
-		Status:  status,
-		Message: message,
+		Status:      status,
+		Message:     message,
+		JSONDetails: jsonDetails,
 	}, nil
 }
@@ -2,7 +2,12 @@ package plugin
 
 import (
 	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/grafana/grafana-plugin-sdk-go/backend"
 )
@@ -28,3 +33,124 @@ func TestQueryData(t *testing.T) {
 		t.Fatal("QueryData must return a response")
 	}
 }
+
+func TestStringListUnmarshal(t *testing.T) {
+	cases := map[string][]string{
+		`"CPUUtilization"`:                        {"CPUUtilization"},
+		`"CPUUtilization, MemUsage"`:              {"CPUUtilization", "MemUsage"},
+		`"{CPUUtilization,MemUsage}"`:             {"CPUUtilization", "MemUsage"},
+		`["CPUUtilization", "{MemUsage,IORead}"]`: {"CPUUtilization", "MemUsage", "IORead"},
+		`["CPUUtilization", "CPUUtilization"]`:    {"CPUUtilization"},
+		`""`:                                      nil,
+	}
+	for input, expected := range cases {
+		var l stringList
+		if err := json.Unmarshal([]byte(input), &l); err != nil {
+			t.Fatalf("unmarshal %s got error, %s", input, err)
+		}
+		if !reflect.DeepEqual([]string(l), expected) {
+			t.Errorf("unmarshal %s expected %v, got %v", input, expected, l)
+		}
+	}
+}
+
+func TestGetPeriod(t *testing.T) {
+	now := time.Now()
+	query := backend.DataQuery{
+		Interval:      15 * time.Second,
+		MaxDataPoints: 1000,
+		TimeRange:     backend.TimeRange{From: now.Add(-time.Hour), To: now},
+	}
+	weekQuery := query
+	weekQuery.TimeRange.From = now.Add(-7 * 24 * time.Hour)
+
+	cases := []struct {
+		period   string
+		query    backend.DataQuery
+		expected int64
+	}{
+		{"", query, 60},
+		{"auto", weekQuery, 3600},
+		{"300", query, 300},
+		{"120", query, 300},
+		{"1h", query, 3600},
+		{"30d", query, 0},
+		{"72h", query, 86400},
+	}
+	for _, c := range cases {
+		period, err := getPeriod(c.period, c.query)
+		if c.expected == 0 {
+			if err == nil {
+				t.Errorf("period %s expected error", c.period)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("period %s got error, %s", c.period, err)
+		} else if period != c.expected {
+			t.Errorf("period %s expected %d, got %d", c.period, c.expected, period)
+		}
+	}
+}
+
+func TestResolveResourcesCached(t *testing.T) {
+	calls := map[string]int{}
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		_ = req.ParseForm()
+		action := req.Form.Get("Action")
+		calls[action]++
+		switch action {
+		case "DescribeUHostInstance":
+			if ids := []string{req.Form.Get("UHostIds.0"), req.Form.Get("UHostIds.1")}; ids[0] != "uhost-2" || ids[1] != "uhost-3" {
+				t.Errorf("expected the ids filtered by the API, got %v", ids)
+			}
+			_, _ = rw.Write([]byte(`{"RetCode":0,"TotalCount":1,"UHostSet":[{"UHostId":"uhost-2","Name":"db"}]}`))
+		default:
+			_, _ = rw.Write([]byte(`{"RetCode":230,"Message":"Params [Region] not available"}`))
+		}
+	})
+	// the labels are cached even if the cache of GetResourceId is disabled
+	d := &UCloudDatasource{apiCache: newTTLCache(), cacheTTLs: map[string]time.Duration{ActionGetResourceId: 0}}
+	qm := queryModel{Region: "cn-bj2", ResourceType: ResourceTypeUHost, ResourceId: stringList{"uhost-3", "uhost-2"}}
+	failing := queryModel{Region: "cn-bj2", ResourceType: ResourceTypeEIP, ResourceId: stringList{"eip-1"}}
+	for i := 0; i < 3; i++ {
+		resources, warning, err := d.resolveResources(client, qm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(resources) != 2 || resources[0].Name != "" || resources[1].Name != "db" || warning != "" {
+			t.Errorf("unexpected resources %+v, %s", resources, warning)
+		}
+		if resources, _, err := d.resolveResources(client, failing); err != nil || len(resources) != 1 {
+			t.Errorf("expected the bare resource of the failed Describe, got %+v, %v", resources, err)
+		}
+	}
+	if calls["DescribeUHostInstance"] != 1 || calls["DescribeEIP"] != 1 {
+		t.Errorf("expected the described resources and the failure cached, got %v", calls)
+	}
+}
+
+func TestNewUCloudDatasourceInvalidSettings(t *testing.T) {
+	instance, err := NewUCloudDatasource(backend.DataSourceInstanceSettings{
+		JSONData:                []byte(`{}`),
+		DecryptedSecureJSONData: map[string]string{"publicKey": "pub", "privateKey": "pri", "tlsCACert": "invalid"},
+	})
+	if err != nil {
+		t.Fatalf("expected the instance built, got error %s", err)
+	}
+	d := instance.(*UCloudDatasource)
+	defer d.Dispose()
+
+	result, err := d.CheckHealth(context.Background(), &backend.CheckHealthRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Status != backend.HealthStatusError || !strings.Contains(result.Message, "tlsCACert") {
+		t.Errorf("expected the settings error reported, got %s %s", result.Status, result.Message)
+	}
+
+	resp, _ := d.QueryData(context.Background(), &backend.QueryDataRequest{Queries: []backend.DataQuery{{RefID: "A"}}})
+	if resp.Responses["A"].Error == nil {
+		t.Error("expected the settings error of the query")
+	}
+}
@@ -0,0 +1,62 @@
+package plugin
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestRateLimiter(t *testing.T) {
+	l := newRateLimiter(100, 2)
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// the burst is served at once, the other 2 calls wait for 10ms each
+	if elapsed := time.Since(start); elapsed < 15*time.Millisecond {
+		t.Errorf("expected to be limited, got %s", elapsed)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := newRateLimiter(1, 1).wait(ctx); err != nil {
+		t.Errorf("expected the burst is not blocked, got %s", err)
+	}
+	l = newRateLimiter(0.001, 1)
+	_ = l.wait(ctx)
+	if err := l.wait(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("expected canceled, got %v", err)
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	for attempt := 0; attempt < 40; attempt++ {
+		if delay := backoff(attempt); delay <= 0 || delay > retryMaxDelay {
+			t.Errorf("backoff of attempt %d is out of range, got %s", attempt, delay)
+		}
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	for _, c := range []struct {
+		err  error
+		want bool
+	}{
+		{&ucloudError{HTTPStatus: 429}, true},
+		{&ucloudError{HTTPStatus: 502}, true},
+		{&ucloudError{HTTPStatus: 403}, false},
+		{&ucloudError{RetCode: 2001}, true},
+		{&ucloudError{RetCode: 171}, false},
+		{&net.OpError{Op: "dial", Err: errors.New("connection refused")}, true},
+		{context.DeadlineExceeded, false},
+		{errors.New("unexpected"), false},
+	} {
+		if got := isRetryable(c.err); got != c.want {
+			t.Errorf("isRetryable(%v), got %v, want %v", c.err, got, c.want)
+		}
+	}
+}
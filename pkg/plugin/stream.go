@@ -0,0 +1,305 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// streamPathPrefix is the prefix of the stream paths, the path is
+	// metric/<projectId>/<region>/<resourceType>/<resourceId>/<metricName>/<period>
+	streamPathPrefix = "metric"
+	// streamDefaultProject is the projectId segment of the path to use the project of the datasource
+	streamDefaultProject = "default"
+
+	defaultStreamInterval = time.Minute
+	// streamWindow is the time range of the points sent to the new subscribers
+	streamWindow = time.Hour
+	// streamBufferSize is the frames buffered for a slow subscriber, the newer frames are dropped once it is full
+	streamBufferSize = 16
+)
+
+// streamKey identifies the series of a stream, the subscribers of the same key share one poller.
+type streamKey struct {
+	ProjectId    string
+	Region       string
+	ResourceType string
+	ResourceId   string
+	Metric       string
+	Period       int64
+}
+
+func parseStreamPath(path string) (streamKey, error) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 7 || parts[0] != streamPathPrefix {
+		return streamKey{}, fmt.Errorf("stream path is invalid, must set to %s/<projectId>/<region>/<resourceType>/<resourceId>/<metricName>/<period>, got %s", streamPathPrefix, path)
+	}
+	for _, part := range parts[1:] {
+		if part == "" {
+			return streamKey{}, fmt.Errorf("stream path is invalid, got empty segment in %s", path)
+		}
+	}
+	period, err := strconv.ParseInt(parts[6], 10, 64)
+	if err != nil || !containsPeriod(period) {
+		return streamKey{}, fmt.Errorf("stream period is invalid, must set to one of %v, got %s", supportedPeriods, parts[6])
+	}
+
+	key := streamKey{
+		ProjectId:    parts[1],
+		Region:       parts[2],
+		ResourceType: parts[3],
+		ResourceId:   parts[4],
+		Metric:       parts[5],
+		Period:       period,
+	}
+	if key.ProjectId == streamDefaultProject {
+		key.ProjectId = ""
+	}
+	return key, nil
+}
+
+func containsPeriod(period int64) bool {
+	for _, v := range supportedPeriods {
+		if v == period {
+			return true
+		}
+	}
+	return false
+}
+
+func (k streamKey) queryModel() queryModel {
+	return queryModel{
+		ProjectId:    k.ProjectId,
+		Region:       k.Region,
+		ResourceType: k.ResourceType,
+		ResourceId:   stringList{k.ResourceId},
+		MetricName:   stringList{k.Metric},
+	}
+}
+
+// window returns the time range fetched by each poll, it covers at least two periods.
+func (k streamKey) window() time.Duration {
+	if d := 2 * time.Duration(k.Period) * time.Second; d > streamWindow {
+		return d
+	}
+	return streamWindow
+}
+
+// streamFetchFunc fetches the series of the stream in the time range, nil means no data.
+type streamFetchFunc func(ctx context.Context, key streamKey, timeRange backend.TimeRange) (*metricSeries, error)
+
+// streamPoller polls the series of a key and pushes the new points to the subscribers.
+type streamPoller struct {
+	key         streamKey
+	subscribers map[chan *data.Frame]struct{}
+	recent      *metricSeries
+	cancel      context.CancelFunc
+}
+
+// streamManager runs one poller per stream key, the poller is started by the first subscriber
+// and stopped once the last subscriber leaves.
+type streamManager struct {
+	mu       sync.Mutex
+	ctx      context.Context
+	cancel   context.CancelFunc
+	interval time.Duration
+	fetch    streamFetchFunc
+	pollers  map[streamKey]*streamPoller
+}
+
+func newStreamManager(interval time.Duration, fetch streamFetchFunc) *streamManager {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &streamManager{
+		ctx:      ctx,
+		cancel:   cancel,
+		interval: interval,
+		fetch:    fetch,
+		pollers:  map[streamKey]*streamPoller{},
+	}
+}
+
+// subscribe registers a subscriber of the key, the recent points are sent at once if the poller is running.
+func (m *streamManager) subscribe(key streamKey) (<-chan *data.Frame, func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ch := make(chan *data.Frame, streamBufferSize)
+	p, ok := m.pollers[key]
+	if !ok {
+		ctx, cancel := context.WithCancel(m.ctx)
+		p = &streamPoller{key: key, subscribers: map[chan *data.Frame]struct{}{}, cancel: cancel}
+		m.pollers[key] = p
+		go m.run(ctx, p)
+	} else if p.recent != nil {
+		ch <- p.recent.frame()
+	}
+	p.subscribers[ch] = struct{}{}
+
+	return ch, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(p.subscribers, ch)
+		if len(p.subscribers) == 0 && m.pollers[key] == p {
+			p.cancel()
+			delete(m.pollers, key)
+		}
+	}
+}
+
+// recent returns the frame of the recent points of the key, nil if the key is not polled yet.
+func (m *streamManager) recent(key streamKey) *data.Frame {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if p, ok := m.pollers[key]; ok && p.recent != nil {
+		return p.recent.frame()
+	}
+	return nil
+}
+
+func (m *streamManager) run(ctx context.Context, p *streamPoller) {
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+	for {
+		m.poll(ctx, p)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the window of the series and sends the points newer than the last sent ones.
+func (m *streamManager) poll(ctx context.Context, p *streamPoller) {
+	now := time.Now()
+	s, err := m.fetch(ctx, p.key, backend.TimeRange{From: now.Add(-p.key.window()), To: now})
+	if err != nil {
+		if ctx.Err() == nil {
+			log.DefaultLogger.Warn("poll stream got error", "resourceId", p.key.ResourceId, "metric", p.key.Metric, "error", err)
+		}
+		return
+	}
+	if s == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var last time.Time
+	if p.recent != nil && len(p.recent.Times) > 0 {
+		last = p.recent.Times[len(p.recent.Times)-1]
+	}
+	update := *s
+	update.Times, update.Values = nil, nil
+	for i, t := range s.Times {
+		if t.After(last) {
+			update.Times = append(update.Times, t)
+			update.Values = append(update.Values, s.Values[i])
+		}
+	}
+	if len(update.Times) == 0 {
+		return
+	}
+	p.recent = s
+
+	frame := update.frame()
+	for ch := range p.subscribers {
+		select {
+		case ch <- frame:
+		default:
+			log.DefaultLogger.Warn("stream subscriber is slow, the frame is dropped", "resourceId", p.key.ResourceId, "metric", p.key.Metric)
+		}
+	}
+}
+
+// close stops all the pollers.
+func (m *streamManager) close() {
+	m.cancel()
+}
+
+// fetchStream fetches the series of the stream by the GetMetric cache, so only the unsettled tail is fetched by each poll.
+func (d *UCloudDatasource) fetchStream(ctx context.Context, key streamKey, timeRange backend.TimeRange) (*metricSeries, error) {
+	if d.settingsErr != nil {
+		return nil, d.settingsErr
+	}
+	client := d.client.withContext(ctx)
+	series, err := d.getMetric(client, key.queryModel(), resource{Id: key.ResourceId, Region: key.Region}, key.Period, timeRange)
+	if err != nil {
+		return nil, err
+	}
+	if len(series) == 0 {
+		return nil, nil
+	}
+
+	s := series[0]
+	infos, err := d.metricInfos.get(client, key.ResourceType)
+	if err != nil {
+		log.DefaultLogger.Warn("describe resource metric got error", "resourceType", key.ResourceType, "error", err)
+	}
+	if info, ok := infos[key.Metric]; ok {
+		s.Info = info
+	}
+	return s, nil
+}
+
+// SubscribeStream is called when a client wants to connect to a stream, the recent points are sent
+// as the initial data if the stream is already polled for other subscribers.
+func (d *UCloudDatasource) SubscribeStream(_ context.Context, req *backend.SubscribeStreamRequest) (*backend.SubscribeStreamResponse, error) {
+	log.DefaultLogger.Info("SubscribeStream called", "request", req)
+
+	key, err := parseStreamPath(req.Path)
+	if err != nil {
+		log.DefaultLogger.Warn("subscribe stream got error", "error", err)
+		return &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusNotFound}, nil
+	}
+
+	response := &backend.SubscribeStreamResponse{Status: backend.SubscribeStreamStatusOK}
+	if frame := d.streams.recent(key); frame != nil {
+		if response.InitialData, err = backend.NewInitialFrame(frame, data.IncludeAll); err != nil {
+			return nil, err
+		}
+	}
+	return response, nil
+}
+
+// RunStream is called once for each stream path, it sends the frames of the shared poller until the stream is closed.
+func (d *UCloudDatasource) RunStream(ctx context.Context, req *backend.RunStreamRequest, sender *backend.StreamSender) error {
+	log.DefaultLogger.Info("RunStream called", "request", req)
+
+	key, err := parseStreamPath(req.Path)
+	if err != nil {
+		return err
+	}
+
+	frames, unsubscribe := d.streams.subscribe(key)
+	defer unsubscribe()
+	for {
+		select {
+		case <-ctx.Done():
+			log.DefaultLogger.Info("stream closed", "path", req.Path)
+			return nil
+		case <-d.streams.ctx.Done():
+			return nil
+		case frame := <-frames:
+			if err := sender.SendFrame(frame, data.IncludeAll); err != nil {
+				log.DefaultLogger.Error("send stream frame got error", "path", req.Path, "error", err)
+			}
+		}
+	}
+}
+
+// PublishStream is called when a client sends a message to the stream, the streams are read only.
+func (d *UCloudDatasource) PublishStream(_ context.Context, req *backend.PublishStreamRequest) (*backend.PublishStreamResponse, error) {
+	log.DefaultLogger.Info("PublishStream called", "request", req)
+
+	return &backend.PublishStreamResponse{
+		Status: backend.PublishStreamStatusPermissionDenied,
+	}, nil
+}
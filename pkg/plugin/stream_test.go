@@ -0,0 +1,90 @@
+package plugin
+
+import (
+	"context"
+	"github.com/grafana/grafana-plugin-sdk-go/backend"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestParseStreamPath(t *testing.T) {
+	key, err := parseStreamPath("metric/default/cn-bj2/uhost/uhost-xxx/CPUUtilization/60")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := streamKey{Region: "cn-bj2", ResourceType: "uhost", ResourceId: "uhost-xxx", Metric: "CPUUtilization", Period: 60}
+	if key != expected {
+		t.Errorf("unexpected key %+v", key)
+	}
+
+	for _, path := range []string{
+		"metric/default/cn-bj2/uhost/uhost-xxx/CPUUtilization",
+		"metric/default/cn-bj2/uhost//CPUUtilization/60",
+		"metric/default/cn-bj2/uhost/uhost-xxx/CPUUtilization/120",
+		"other/default/cn-bj2/uhost/uhost-xxx/CPUUtilization/60",
+	} {
+		if _, err := parseStreamPath(path); err == nil {
+			t.Errorf("expected error of %s", path)
+		}
+	}
+}
+
+func TestStreamManager(t *testing.T) {
+	var mu sync.Mutex
+	var calls int
+	base := time.Unix(1600000000, 0)
+	fetch := func(ctx context.Context, key streamKey, timeRange backend.TimeRange) (*metricSeries, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		s := &metricSeries{Name: key.ResourceId, Metric: key.Metric}
+		for i := 0; i <= calls; i++ {
+			s.Times = append(s.Times, base.Add(time.Duration(i)*time.Minute))
+			s.Values = append(s.Values, float64(i))
+		}
+		return s, nil
+	}
+
+	m := newStreamManager(20*time.Millisecond, fetch)
+	defer m.close()
+	key := streamKey{Region: "cn-bj2", ResourceType: "uhost", ResourceId: "uhost-xxx", Metric: "CPUUtilization", Period: 60}
+
+	first, unsubscribeFirst := m.subscribe(key)
+	frame := receiveFrame(t, first)
+	if frame.Rows() != 2 {
+		t.Errorf("expected the window of 2 points, got %d", frame.Rows())
+	}
+	frame = receiveFrame(t, first)
+	if frame.Rows() != 1 {
+		t.Errorf("expected the new point only, got %d", frame.Rows())
+	}
+
+	second, unsubscribeSecond := m.subscribe(key)
+	if frame = receiveFrame(t, second); frame.Rows() < 3 {
+		t.Errorf("expected the recent points, got %d", frame.Rows())
+	}
+	if len(m.pollers) != 1 {
+		t.Errorf("expected one shared poller, got %d", len(m.pollers))
+	}
+
+	unsubscribeFirst()
+	unsubscribeSecond()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.pollers) != 0 {
+		t.Errorf("expected the poller stopped, got %d", len(m.pollers))
+	}
+}
+
+func receiveFrame(t *testing.T, frames <-chan *data.Frame) *data.Frame {
+	t.Helper()
+	select {
+	case frame := <-frames:
+		return frame
+	case <-time.After(time.Second):
+		t.Fatal("expected frame")
+	}
+	return nil
+}
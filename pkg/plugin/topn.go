@@ -0,0 +1,133 @@
+package plugin
+
+import (
+	"fmt"
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"sort"
+)
+
+const (
+	TopOrderTop    = "top"
+	TopOrderBottom = "bottom"
+
+	defaultTopReducer = "avg"
+)
+
+// rankedSeries is a series with the value it is ranked by.
+type rankedSeries struct {
+	series *metricSeries
+	value  float64
+}
+
+// checkTopN validates the Top-N settings of the query before the metrics are fetched.
+func checkTopN(qm queryModel) error {
+	if qm.TopN < 0 {
+		return fmt.Errorf("topN is invalid, must set to positive int value, got %d", qm.TopN)
+	}
+	switch qm.TopOrder {
+	case "", TopOrderTop, TopOrderBottom:
+	default:
+		return fmt.Errorf("topOrder is invalid, must set to %s or %s, got %s", TopOrderTop, TopOrderBottom, qm.TopOrder)
+	}
+	_, err := getTopReducer(qm.TopReducer)
+	return err
+}
+
+// getTopReducer returns the function to reduce a series to the value it is ranked by,
+// supports last besides the aggregations like avg and max, empty means avg.
+func getTopReducer(reducer string) (reduceFunc, error) {
+	switch reducer {
+	case "":
+		reducer = defaultTopReducer
+	case "last":
+		return func(values []float64) float64 {
+			return values[len(values)-1]
+		}, nil
+	}
+	reduce, err := getReduceFunc(reducer)
+	if err != nil {
+		return nil, fmt.Errorf("topReducer is invalid, must set to avg, max, min, last or percentile like p95, got %s", reducer)
+	}
+	return reduce, nil
+}
+
+// rankSeries reduces each series and returns the first n series of each metric ordered by the reduced values,
+// descending for top and ascending for bottom. The series without data are not ranked, n of 0 keeps all.
+func rankSeries(series []*metricSeries, reducer, order string, n int) ([]rankedSeries, error) {
+	reduce, err := getTopReducer(reducer)
+	if err != nil {
+		return nil, err
+	}
+
+	var metrics []string
+	byMetric := map[string][]rankedSeries{}
+	for _, s := range series {
+		if len(s.Values) == 0 {
+			continue
+		}
+		if _, ok := byMetric[s.Metric]; !ok {
+			metrics = append(metrics, s.Metric)
+		}
+		byMetric[s.Metric] = append(byMetric[s.Metric], rankedSeries{series: s, value: reduce(s.Values)})
+	}
+
+	var result []rankedSeries
+	for _, metric := range metrics {
+		ranked := byMetric[metric]
+		sort.SliceStable(ranked, func(i, j int) bool {
+			if order == TopOrderBottom {
+				return ranked[i].value < ranked[j].value
+			}
+			return ranked[i].value > ranked[j].value
+		})
+		if n > 0 && len(ranked) > n {
+			ranked = ranked[:n]
+		}
+		result = append(result, ranked...)
+	}
+	return result, nil
+}
+
+// rankedTable returns the ranked series as a table, one row per series with the rank of its metric,
+// the labels and the reduced value.
+func rankedTable(ranked []rankedSeries) *data.Frame {
+	labelKeys := []string{"resourceId", "name", "tag", "zone", "region"}
+	ranks := make([]int64, 0, len(ranked))
+	metrics := make([]string, 0, len(ranked))
+	labels := make([][]string, len(labelKeys))
+	values := make([]float64, 0, len(ranked))
+
+	var metric string
+	var rank int64
+	for _, r := range ranked {
+		if r.series.Metric != metric {
+			metric, rank = r.series.Metric, 0
+		}
+		rank++
+		ranks = append(ranks, rank)
+		metrics = append(metrics, r.series.Metric)
+		for i, k := range labelKeys {
+			labels[i] = append(labels[i], r.series.Labels[k])
+		}
+		values = append(values, r.value)
+	}
+
+	frame := data.NewFrame("ranking",
+		data.NewField("rank", nil, ranks),
+		data.NewField("metric", nil, metrics),
+	)
+	for i, k := range labelKeys {
+		if labels[i] == nil {
+			labels[i] = []string{}
+		}
+		frame.Fields = append(frame.Fields, data.NewField(k, nil, labels[i]))
+	}
+	value := data.NewField("value", nil, values)
+	// the unit is only meaningful if all the rows are of the same metric
+	if len(ranked) > 0 && ranked[0].series.Metric == ranked[len(ranked)-1].series.Metric {
+		value.SetConfig(ranked[0].series.Info.fieldConfig())
+	}
+	frame.Fields = append(frame.Fields, value)
+	frame.SetMeta(&data.FrameMeta{PreferredVisualization: data.VisTypeTable})
+	return frame
+}
@@ -0,0 +1,71 @@
+package plugin
+
+import (
+	"github.com/grafana/grafana-plugin-sdk-go/data"
+	"testing"
+)
+
+func TestRankSeries(t *testing.T) {
+	newSeries := func(id, metric string, values ...float64) *metricSeries {
+		return &metricSeries{Name: id, Metric: metric, Labels: data.Labels{"resourceId": id}, Values: values}
+	}
+	series := []*metricSeries{
+		newSeries("uhost-a", "CPUUtilization", 10, 90),
+		newSeries("uhost-b", "CPUUtilization", 60, 60),
+		newSeries("uhost-c", "CPUUtilization", 20, 30),
+		newSeries("uhost-d", "CPUUtilization"),
+		newSeries("uhost-a", "MemUsage", 40),
+	}
+
+	cases := []struct {
+		reducer  string
+		order    string
+		n        int
+		expected []string
+	}{
+		{"", "", 2, []string{"uhost-b", "uhost-a", "uhost-a"}},
+		{"max", "top", 1, []string{"uhost-a", "uhost-a"}},
+		{"last", "bottom", 2, []string{"uhost-c", "uhost-b", "uhost-a"}},
+		{"avg", "bottom", 0, []string{"uhost-c", "uhost-a", "uhost-b", "uhost-a"}},
+	}
+	for _, c := range cases {
+		ranked, err := rankSeries(series, c.reducer, c.order, c.n)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, r := range ranked {
+			ids = append(ids, r.series.Name)
+		}
+		if len(ids) != len(c.expected) {
+			t.Errorf("%s %s %d expected %v, got %v", c.reducer, c.order, c.n, c.expected, ids)
+			continue
+		}
+		for i := range ids {
+			if ids[i] != c.expected[i] {
+				t.Errorf("%s %s %d expected %v, got %v", c.reducer, c.order, c.n, c.expected, ids)
+				break
+			}
+		}
+	}
+
+	if err := checkTopN(queryModel{TopN: 10, TopReducer: "median"}); err == nil {
+		t.Error("expected error of invalid reducer")
+	}
+	if err := checkTopN(queryModel{TopN: 10, TopOrder: "desc"}); err == nil {
+		t.Error("expected error of invalid order")
+	}
+
+	ranked, _ := rankSeries(series, "max", "top", 0)
+	frame := rankedTable(ranked)
+	if frame.Rows() != 4 {
+		t.Fatalf("expected 4 rows, got %d", frame.Rows())
+	}
+	// the rank restarts for each metric
+	if v, _ := frame.ConcreteAt(0, 3); v != int64(1) {
+		t.Errorf("unexpected rank %v", v)
+	}
+	if v, _ := frame.ConcreteAt(7, 0); v != 90.0 {
+		t.Errorf("unexpected value %v", v)
+	}
+}
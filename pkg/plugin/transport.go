@@ -0,0 +1,148 @@
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/grafana/grafana-plugin-sdk-go/backend/log"
+	uhttp "github.com/ucloud/ucloud-sdk-go/private/protocol/http"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+)
+
+// sdkHttpClient sends the requests of the UCloud SDK by the http client built from the datasource
+// settings, it replaces the default client of the SDK which always uses the default transport.
+// All the API calls of a datasource share the rate limiter and the concurrency cap, the throttled
+// and transient failures are retried with exponential backoff.
+type sdkHttpClient struct {
+	client     *http.Client
+	limiter    *rateLimiter
+	sem        chan struct{}
+	maxRetries int
+
+	// ctx aborts the requests in flight and the retries when it is done
+	ctx context.Context
+}
+
+// withContext returns a copy of the http client bound to the context.
+func (c *sdkHttpClient) withContext(ctx context.Context) *sdkHttpClient {
+	bound := *c
+	bound.ctx = ctx
+	return &bound
+}
+
+func (c *sdkHttpClient) Send(req *uhttp.HttpRequest) (*uhttp.HttpResponse, error) {
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, req)
+		if err == nil || attempt >= c.maxRetries || !isRetryable(err) {
+			return resp, err
+		}
+		delay := backoff(attempt)
+		log.DefaultLogger.Debug("retry the ucloud api", "url", req.GetURL(), "attempt", attempt+1, "delay", delay, "error", err)
+		if err := sleep(ctx, delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// send sends the request once within the rate limit and the concurrency cap.
+func (c *sdkHttpClient) send(ctx context.Context, req *uhttp.HttpRequest) (*uhttp.HttpResponse, error) {
+	if err := c.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	if c.sem != nil {
+		select {
+		case c.sem <- struct{}{}:
+			defer func() { <-c.sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if timeout := req.GetTimeout(); timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	httpReq, err := newHttpRequest(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	httpResp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	body, err := ioutil.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	// the request id is only in the header, it is lost once the response is returned to the SDK
+	if e := newUCloudError(getAction(req), httpResp, body); e != nil {
+		return nil, e
+	}
+
+	resp := uhttp.NewHttpResponse()
+	resp.SetStatusCode(httpResp.StatusCode)
+	_ = resp.SetBody(body)
+	return resp, nil
+}
+
+// getAction returns the action of the request from the query string or the form body.
+func getAction(req *uhttp.HttpRequest) string {
+	if qs, err := req.BuildQueryString(); err == nil {
+		if values, err := url.ParseQuery(qs); err == nil && values.Get("Action") != "" {
+			return values.Get("Action")
+		}
+	}
+	if values, err := url.ParseQuery(string(req.GetRequestBody())); err == nil {
+		return values.Get("Action")
+	}
+	return ""
+}
+
+// isRetryable returns if the error is the throttling or the transient network error.
+func isRetryable(err error) bool {
+	if isCancelled(err) {
+		return false
+	}
+	var e *ucloudError
+	if errors.As(err, &e) {
+		return e.Retryable()
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// newHttpRequest converts the request of the UCloud SDK to the http request.
+func newHttpRequest(ctx context.Context, req *uhttp.HttpRequest) (*http.Request, error) {
+	qs, err := req.BuildQueryString()
+	if err != nil {
+		return nil, err
+	}
+	uri := req.GetURL()
+	if qs != "" {
+		uri += "?" + qs
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, req.GetMethod(), uri, bytes.NewReader(req.GetRequestBody()))
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range uhttp.DefaultHeaders {
+		httpReq.Header.Set(k, v)
+	}
+	for k, v := range req.GetHeaderMap() {
+		httpReq.Header.Set(k, v)
+	}
+	return httpReq, nil
+}
@@ -0,0 +1,152 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	uerr "github.com/ucloud/ucloud-sdk-go/ucloud/error"
+	"net/http"
+)
+
+// headerRequestUUID is the response header of the request id of UCloud API.
+const headerRequestUUID = "X-UCLOUD-REQUEST-UUID"
+
+// retCodeServerError is the lower bound of RetCode of the transient server errors, the SDK regards them as retryable too.
+const retCodeServerError = 2000
+
+// ucloudError is the error response of UCloud API, it is parsed from the non-zero RetCode or
+// the failed HTTP status. It implements uerr.Error so the SDK returns it to the callers as is.
+type ucloudError struct {
+	Action      string
+	RetCode     int
+	RequestUUID string
+	HTTPStatus  int
+
+	message string
+}
+
+// newUCloudError parses the error response of the action, it returns nil if RetCode of the body is 0.
+func newUCloudError(action string, httpResp *http.Response, body []byte) *ucloudError {
+	var resp struct {
+		Action  string
+		RetCode int
+		Message string
+	}
+	_ = json.Unmarshal(body, &resp)
+	if resp.RetCode == 0 && httpResp.StatusCode < 400 {
+		return nil
+	}
+
+	e := &ucloudError{
+		Action:      action,
+		RetCode:     resp.RetCode,
+		RequestUUID: httpResp.Header.Get(headerRequestUUID),
+		message:     resp.Message,
+	}
+	if httpResp.StatusCode >= 400 {
+		e.HTTPStatus = httpResp.StatusCode
+		if e.message == "" {
+			e.message = httpResp.Status
+		}
+	}
+	return e
+}
+
+func (e *ucloudError) Error() string {
+	var msg string
+	if e.HTTPStatus > 0 {
+		msg = fmt.Sprintf("do %s got http status %d, %s", e.Action, e.HTTPStatus, e.message)
+	} else {
+		msg = fmt.Sprintf("do %s got RetCode %d, %s", e.Action, e.RetCode, e.message)
+	}
+	if e.RequestUUID != "" {
+		msg += fmt.Sprintf(" (RequestUUID %s)", e.RequestUUID)
+	}
+	return msg
+}
+
+func (e *ucloudError) Name() string {
+	if e.HTTPStatus > 0 {
+		return uerr.ErrHTTPStatus
+	}
+	return uerr.ErrRetCode
+}
+
+func (e *ucloudError) Code() int {
+	return e.RetCode
+}
+
+func (e *ucloudError) StatusCode() int {
+	if e.HTTPStatus > 0 {
+		return e.HTTPStatus
+	}
+	return http.StatusOK
+}
+
+func (e *ucloudError) Message() string {
+	return e.message
+}
+
+func (e *ucloudError) OriginError() error {
+	return errors.New(e.message)
+}
+
+// Retryable returns if the error is the throttling or the transient server error.
+func (e *ucloudError) Retryable() bool {
+	return e.RetCode >= retCodeServerError || e.HTTPStatus == http.StatusTooManyRequests || e.HTTPStatus >= http.StatusInternalServerError
+}
+
+// isCancelled returns if the error is caused by the cancelled or timed out context. The SDK wraps the
+// transport errors in uerr.ClientError without Unwrap, so the origin errors are checked as well.
+func isCancelled(err error) bool {
+	for err != nil {
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return true
+		}
+		var uErr uerr.Error
+		if !errors.As(err, &uErr) {
+			return false
+		}
+		origin := uErr.OriginError()
+		if origin == nil || origin.Error() == err.Error() {
+			return false
+		}
+		err = origin
+	}
+	return false
+}
+
+// panelError returns the human-friendly message of the error shown in the panels,
+// RetCode and RequestUUID are kept to file the support tickets.
+func panelError(err error) string {
+	if isCancelled(err) {
+		return "the query is cancelled or timed out"
+	}
+	var e *ucloudError
+	if !errors.As(err, &e) {
+		return err.Error()
+	}
+
+	var msg string
+	switch {
+	case isSignatureError(e):
+		msg = "signature verification failed, please check the Public Key and Private Key"
+	case isPermissionError(e):
+		msg = fmt.Sprintf("the keys have no permission to %s, %s", e.Action, e.message)
+	case e.Retryable():
+		msg = fmt.Sprintf("UCloud API is busy or unavailable, %s", e.message)
+	default:
+		msg = fmt.Sprintf("%s failed, %s", e.Action, e.message)
+	}
+
+	if e.HTTPStatus > 0 {
+		msg += fmt.Sprintf(" (HTTP %d", e.HTTPStatus)
+	} else {
+		msg += fmt.Sprintf(" (RetCode %d", e.RetCode)
+	}
+	if e.RequestUUID != "" {
+		msg += fmt.Sprintf(", RequestUUID %s", e.RequestUUID)
+	}
+	return msg + ")"
+}
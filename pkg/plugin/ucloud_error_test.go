@@ -0,0 +1,32 @@
+package plugin
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestUCloudError(t *testing.T) {
+	client := newTestClient(t, func(rw http.ResponseWriter, req *http.Request) {
+		rw.Header().Set(headerRequestUUID, "2c4a7d5e-uuid")
+		_, _ = rw.Write([]byte(`{"Action":"GetMetricResponse","RetCode":230,"Message":"Params [Region] not available"}`))
+	})
+	_, err := client.getMetricData(queryModel{Region: "cn-xx", ResourceType: "uhost"}, resource{Id: "uhost-xxx"}, 60, 0, 60)
+
+	var e *ucloudError
+	if !errors.As(err, &e) {
+		t.Fatalf("expected ucloudError, got %#v", err)
+	}
+	if e.Action != "GetMetric" || e.RetCode != 230 || e.Message() != "Params [Region] not available" || e.RequestUUID != "2c4a7d5e-uuid" {
+		t.Errorf("unexpected error %+v", e)
+	}
+	if msg := panelError(err); !strings.Contains(msg, "RetCode 230") || !strings.Contains(msg, "RequestUUID 2c4a7d5e-uuid") {
+		t.Errorf("unexpected panel error %s", msg)
+	}
+
+	apiErr := newAPIError("GetResourceId", err)
+	if apiErr.status != http.StatusBadRequest || apiErr.RequestId != "2c4a7d5e-uuid" || apiErr.Action != "GetMetric" {
+		t.Errorf("unexpected api error %+v", apiErr)
+	}
+}
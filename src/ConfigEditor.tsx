@@ -1,9 +1,26 @@
 import React, { ChangeEvent, PureComponent } from 'react';
-import { LegacyForms } from '@grafana/ui';
+import { Button, InlineFormLabel, LegacyForms, TextArea } from '@grafana/ui';
 import { DataSourcePluginOptionsEditorProps } from '@grafana/data';
 import { MyDataSourceOptions, MySecureJsonData } from './types';
 
-const { SecretFormField, FormField } = LegacyForms;
+const { SecretFormField, FormField, Input, Switch } = LegacyForms;
+
+// parseSecondsMap parses the seconds formatted as uhost=60,udb=60
+const parseSecondsMap = (value: string) => {
+  const result: { [key: string]: number } = {};
+  value.split(',').forEach((item) => {
+    const [key, seconds] = item.split('=').map((v) => v.trim());
+    if (key && seconds && !isNaN(parseInt(seconds, 10))) {
+      result[key] = parseInt(seconds, 10);
+    }
+  });
+  return result;
+};
+
+const formatSecondsMap = (value?: { [key: string]: number }) =>
+  Object.entries(value || {})
+    .map(([key, seconds]) => `${key}=${seconds}`)
+    .join(',');
 
 interface Props extends DataSourcePluginOptionsEditorProps<MyDataSourceOptions> {}
 
@@ -19,6 +36,38 @@ export class ConfigEditor extends PureComponent<Props, State> {
     onOptionsChange({ ...options, jsonData });
   };
 
+  onJsonDataChange = (key: keyof MyDataSourceOptions, value: any) => {
+    const { onOptionsChange, options } = this.props;
+    const jsonData = {
+      ...options.jsonData,
+      [key]: value,
+    };
+    onOptionsChange({ ...options, jsonData });
+  };
+
+  onTimeoutChange = (event: ChangeEvent<HTMLInputElement>) => {
+    const timeout = parseInt(event.target.value, 10);
+    this.onJsonDataChange('timeout', isNaN(timeout) ? undefined : timeout);
+  };
+
+  onMaxResourcesChange = (event: ChangeEvent<HTMLInputElement>) => {
+    const maxResources = parseInt(event.target.value, 10);
+    this.onJsonDataChange('maxResources', isNaN(maxResources) ? undefined : maxResources);
+  };
+
+  onNumberChange = (key: keyof MyDataSourceOptions) => (event: ChangeEvent<HTMLInputElement>) => {
+    const value = parseFloat(event.target.value);
+    this.onJsonDataChange(key, isNaN(value) ? undefined : value);
+  };
+
+  onServiceTimeoutsChange = (event: ChangeEvent<HTMLInputElement>) => {
+    this.onJsonDataChange('serviceTimeouts', parseSecondsMap(event.target.value));
+  };
+
+  onCacheTTLsChange = (event: ChangeEvent<HTMLInputElement>) => {
+    this.onJsonDataChange('cacheTTLs', parseSecondsMap(event.target.value));
+  };
+
   // Secure field (only sent to the backend)
   onPublicKeyChange = (event: ChangeEvent<HTMLInputElement>) => {
     const { onOptionsChange, options } = this.props;
@@ -72,6 +121,32 @@ export class ConfigEditor extends PureComponent<Props, State> {
     });
   };
 
+  onTLSCACertChange = (event: ChangeEvent<HTMLTextAreaElement>) => {
+    const { onOptionsChange, options } = this.props;
+    onOptionsChange({
+      ...options,
+      secureJsonData: {
+        ...options.secureJsonData,
+        tlsCACert: event.target.value,
+      },
+    });
+  };
+
+  onResetTLSCACert = () => {
+    const { onOptionsChange, options } = this.props;
+    onOptionsChange({
+      ...options,
+      secureJsonFields: {
+        ...options.secureJsonFields,
+        tlsCACert: false,
+      },
+      secureJsonData: {
+        ...options.secureJsonData,
+        tlsCACert: '',
+      },
+    });
+  };
+
   render() {
     const { options } = this.props;
     const { jsonData, secureJsonFields } = options;
@@ -121,6 +196,164 @@ export class ConfigEditor extends PureComponent<Props, State> {
             />
           </div>
         </div>
+
+        <h3 className="page-heading">Connection</h3>
+
+        <div className="gf-form">
+          <FormField
+            label="Base URL"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={(e: ChangeEvent<HTMLInputElement>) => this.onJsonDataChange('baseUrl', e.target.value)}
+            value={jsonData.baseUrl || ''}
+            placeholder="https://api.ucloud.cn"
+            tooltip="The API endpoint, set it for the private or hybrid cloud"
+          />
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="HTTP Proxy"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={(e: ChangeEvent<HTMLInputElement>) => this.onJsonDataChange('httpProxy', e.target.value)}
+            value={jsonData.httpProxy || ''}
+            placeholder="http://proxy.example.com:8080"
+            tooltip="The HTTP(S) proxy of the API requests, use the proxy environment variables by default"
+          />
+        </div>
+
+        <div className="gf-form">
+          <Switch
+            label="Skip TLS Verify"
+            labelClass="width-10"
+            checked={jsonData.tlsSkipVerify || false}
+            onChange={(e) => this.onJsonDataChange('tlsSkipVerify', e.currentTarget.checked)}
+          />
+        </div>
+
+        {/* the PEM keeps its line breaks in the text area, a single line input would drop them */}
+        <div className="gf-form-inline">
+          <div className="gf-form gf-form--v-stretch">
+            <InlineFormLabel width={10} tooltip="The PEM encoded certificates of the private API endpoint">
+              CA Cert
+            </InlineFormLabel>
+          </div>
+          {secureJsonFields && secureJsonFields.tlsCACert ? (
+            <div className="gf-form">
+              <Input type="text" className="width-20" disabled value="configured" />
+              <Button variant="secondary" onClick={this.onResetTLSCACert}>
+                Reset
+              </Button>
+            </div>
+          ) : (
+            <div className="gf-form gf-form--grow">
+              <TextArea
+                rows={7}
+                className="gf-form-input gf-form-textarea"
+                value={secureJsonData.tlsCACert || ''}
+                placeholder="Begins with -----BEGIN CERTIFICATE-----"
+                onChange={this.onTLSCACertChange}
+              />
+            </div>
+          )}
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="Timeout"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={this.onTimeoutChange}
+            value={jsonData.timeout || ''}
+            placeholder="30"
+            tooltip="The timeout of the API requests in seconds"
+          />
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="Service Timeouts"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={this.onServiceTimeoutsChange}
+            defaultValue={formatSecondsMap(jsonData.serviceTimeouts)}
+            placeholder="uhost=60,udb=60"
+            tooltip="The timeouts in seconds of the services, override the timeout above"
+          />
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="Max Resources"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={this.onMaxResourcesChange}
+            value={jsonData.maxResources || ''}
+            placeholder="1000"
+            tooltip="The max number of resources fetched by a single resource discovery"
+          />
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="Cache TTLs"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={this.onCacheTTLsChange}
+            defaultValue={formatSecondsMap(jsonData.cacheTTLs)}
+            placeholder="GetResourceId=60,GetRegion=3600"
+            tooltip="The cache ttl in seconds of the variable queries by Action, 0 disables the cache"
+          />
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="Rate Limit"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={this.onNumberChange('rateLimit')}
+            value={jsonData.rateLimit ?? ''}
+            placeholder="20"
+            tooltip="The API calls per second, -1 disables the rate limit"
+          />
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="Max Concurrency"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={this.onNumberChange('maxConcurrency')}
+            value={jsonData.maxConcurrency ?? ''}
+            placeholder="10"
+            tooltip="The max API calls in flight, -1 disables the cap"
+          />
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="Max Retries"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={this.onNumberChange('maxRetries')}
+            value={jsonData.maxRetries ?? ''}
+            placeholder="3"
+            tooltip="The retries of the throttled and transient failures with exponential backoff, 0 disables the retries"
+          />
+        </div>
+
+        <div className="gf-form">
+          <FormField
+            label="Stream Interval"
+            labelWidth={10}
+            inputWidth={20}
+            onChange={this.onNumberChange('streamInterval')}
+            value={jsonData.streamInterval ?? ''}
+            placeholder="60"
+            tooltip="The polling interval in seconds of the live streams"
+          />
+        </div>
       </div>
     );
   }
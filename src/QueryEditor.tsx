@@ -17,6 +17,60 @@ export class QueryEditor extends PureComponent<Props> {
   }
 }
 
+const queryTypes: SelectableStrings = [
+  { label: 'metrics', value: 'metrics' },
+  { label: 'alarms', value: 'alarms' },
+  { label: 'resources', value: 'resources' },
+];
+
+const periods: SelectableStrings = [
+  { label: 'auto', value: 'auto' },
+  { label: '1m', value: '60' },
+  { label: '5m', value: '300' },
+  { label: '1h', value: '3600' },
+  { label: '1d', value: '86400' },
+];
+
+const aggregations: SelectableStrings = [
+  { label: 'none', value: '' },
+  { label: 'sum', value: 'sum' },
+  { label: 'avg', value: 'avg' },
+  { label: 'min', value: 'min' },
+  { label: 'max', value: 'max' },
+  { label: 'count', value: 'count' },
+  { label: 'p95', value: 'p95' },
+];
+
+const groupBys: SelectableStrings = [
+  { label: 'none', value: '' },
+  { label: 'tag', value: 'tag' },
+  { label: 'zone', value: 'zone' },
+  { label: 'region', value: 'region' },
+];
+
+const formats: SelectableStrings = [
+  { label: 'time series', value: 'time_series' },
+  { label: 'long', value: 'long' },
+  { label: 'table', value: 'table' },
+];
+
+const topReducers: SelectableStrings = [
+  { label: 'avg', value: 'avg' },
+  { label: 'max', value: 'max' },
+  { label: 'min', value: 'min' },
+  { label: 'last', value: 'last' },
+];
+
+const topOrders: SelectableStrings = [
+  { label: 'top', value: 'top' },
+  { label: 'bottom', value: 'bottom' },
+];
+
+const streams: Array<SelectableValue<boolean>> = [
+  { label: 'off', value: false },
+  { label: 'on', value: true },
+];
+
 interface State {
   projectIds: SelectableStrings;
   regions: SelectableStrings;
@@ -92,6 +146,16 @@ const MetricsQueryFieldsEditor = (props: any) => {
   console.log('projectIds:///', projectIds);
   return (
     <>
+      <QueryInlineField
+        label="QueryType"
+        tooltip="Metrics queries the time series, alarms queries the alarm history as annotations, resources queries the inventory table"
+      >
+        <Segment
+          value={query.queryType || 'metrics'}
+          options={queryTypes}
+          onChange={({ value: queryType }) => onQueryChange({ ...query, queryType: queryType! })}
+        />
+      </QueryInlineField>
       <QueryInlineField label="ProjectId">
         <Segment
           value={query.projectId}
@@ -137,6 +201,86 @@ const MetricsQueryFieldsEditor = (props: any) => {
           onChange={({ value: resourceId }) => onQueryChange({ ...query, resourceId: resourceId! })}
         />
       </QueryInlineField>
+      <QueryInlineField label="Period" tooltip="Sampling interval, auto is derived from the panel interval">
+        <Segment
+          value={query.period || 'auto'}
+          options={periods}
+          allowCustomValue
+          onChange={({ value: period }) => onQueryChange({ ...query, period: period! })}
+        />
+      </QueryInlineField>
+      <QueryInlineField label="Aggregation" tooltip="Combine the series of all the resources into one series">
+        <Segment
+          value={query.aggregation || 'none'}
+          options={aggregations}
+          allowCustomValue
+          onChange={({ value: aggregation }) => onQueryChange({ ...query, aggregation: aggregation! })}
+        />
+        {query.aggregation ? (
+          <Segment
+            value={query.groupBy || 'group by'}
+            options={groupBys}
+            allowCustomValue
+            onChange={({ value: groupBy }) => onQueryChange({ ...query, groupBy: groupBy! })}
+          />
+        ) : null}
+      </QueryInlineField>
+      <QueryInlineField
+        label="Alias"
+        tooltip="Legend format, supports {{resourceId}}, {{name}}, {{tag}}, {{zone}}, {{region}}, {{metric}} and {{displayName}}"
+      >
+        <Input
+          className="gf-form-input width-20"
+          placeholder="{{name}} {{metric}}"
+          value={query.alias || ''}
+          onBlur={onRunQuery}
+          onChange={(v) => onChange({ ...query, alias: v.target.value })}
+        />
+      </QueryInlineField>
+      <QueryInlineField
+        label="Format"
+        tooltip="Time series returns one frame per series with the labels, long returns a single table with the label columns, table returns the series ranked by the reduced values"
+      >
+        <Segment
+          value={formats.find((f) => f.value === (query.format || 'time_series'))}
+          options={formats}
+          onChange={({ value: format }) => onQueryChange({ ...query, format: format! })}
+        />
+      </QueryInlineField>
+      <QueryInlineField
+        label="Top N"
+        tooltip="Keep only the first N series of each metric ranked by the reduced values, empty or 0 keeps all the series"
+      >
+        <Input
+          className="gf-form-input width-6"
+          type="number"
+          placeholder="0"
+          value={query.topN || ''}
+          onBlur={onRunQuery}
+          onChange={(v) => onChange({ ...query, topN: parseInt(v.target.value, 10) || undefined })}
+        />
+        <Segment
+          value={query.topReducer || 'avg'}
+          options={topReducers}
+          allowCustomValue
+          onChange={({ value: topReducer }) => onQueryChange({ ...query, topReducer: topReducer! })}
+        />
+        <Segment
+          value={query.topOrder || 'top'}
+          options={topOrders}
+          onChange={({ value: topOrder }) => onQueryChange({ ...query, topOrder: topOrder! })}
+        />
+      </QueryInlineField>
+      <QueryInlineField
+        label="Stream"
+        tooltip="Push the newest points over Grafana Live, only the explicit resource ids are streamed"
+      >
+        <Segment
+          value={query.stream ? 'on' : 'off'}
+          options={streams}
+          onChange={({ value: stream }) => onQueryChange({ ...query, stream: stream! })}
+        />
+      </QueryInlineField>
     </>
   );
 };
@@ -144,7 +288,7 @@ const MetricsQueryFieldsEditor = (props: any) => {
 const QueryResourceIdCollapse = (props: any) => {
   const [isOpen, setIsOpen] = useState(false);
   const { onChange, query, onRunQuery } = props;
-  const { tag, limit, offset, ulbId, classType } = query;
+  const { tag, resourceName, limit, offset, ulbId, classType } = query;
   const onQueryChange = (query: MyQuery) => {
     onChange(query);
     onRunQuery();
@@ -216,6 +360,20 @@ const QueryResourceIdCollapse = (props: any) => {
               />
             </QueryField>
           </div>
+          <div className="gf-form gf-form--grow">
+            <QueryField
+              className="gf-form--grow"
+              label="ResourceName"
+              tooltip="Regex to match the resource name when ResourceId is * or empty"
+            >
+              <Input
+                className="gf-form-input"
+                onBlur={onRunQuery}
+                value={resourceName}
+                onChange={(v) => onQueryChange({ ...query, resourceName: v.target.value! })}
+              />
+            </QueryField>
+          </div>
         </div>
       </Collapse>
     </div>
@@ -1,10 +1,22 @@
-import { DataSourceInstanceSettings, ScopedVars } from '@grafana/data';
-import { DataSourceWithBackend, getTemplateSrv } from '@grafana/runtime';
+import {
+  DataQueryRequest,
+  DataQueryResponse,
+  DataSourceInstanceSettings,
+  LiveChannelScope,
+  ScopedVars,
+} from '@grafana/data';
+import { DataSourceWithBackend, getGrafanaLiveSrv, getTemplateSrv } from '@grafana/runtime';
+import { merge, Observable } from 'rxjs';
 import { MyDataSourceOptions, MyQuery } from './types';
 
+// streamPeriods are the periods supported by the streams, the auto period is streamed by 60 seconds
+const streamPeriods = ['60', '300', '3600', '86400'];
+
 export class DataSource extends DataSourceWithBackend<MyQuery, MyDataSourceOptions> {
   constructor(instanceSettings: DataSourceInstanceSettings<MyDataSourceOptions>) {
     super(instanceSettings);
+    // the annotations are queried by the query editor with the alarms query type
+    this.annotations = {};
   }
 
   applyTemplateVariables(query: MyQuery, scopedVars: ScopedVars): Record<string, any> {
@@ -13,12 +25,54 @@ export class DataSource extends DataSourceWithBackend<MyQuery, MyDataSourceOptio
     query.resourceType = getTemplateSrv().replace(query.resourceType);
     query.metricName = getTemplateSrv().replace(query.metricName);
     query.resourceId = getTemplateSrv().replace(query.resourceId);
+    query.period = getTemplateSrv().replace(query.period || '');
+    query.aggregation = getTemplateSrv().replace(query.aggregation || '');
+    query.groupBy = getTemplateSrv().replace(query.groupBy || '');
+    query.alias = getTemplateSrv().replace(query.alias || '', scopedVars);
     query.tag = getTemplateSrv().replace(query.tag);
+    query.resourceName = getTemplateSrv().replace(query.resourceName);
     query.ulbId = getTemplateSrv().replace(query.ulbId);
     query.classType = getTemplateSrv().replace(query.classType);
     return super.applyTemplateVariables(query, scopedVars);
   }
 
+  query(request: DataQueryRequest<MyQuery>): Observable<DataQueryResponse> {
+    const streamed = request.targets.filter((t) => t.stream && !t.hide);
+    if (streamed.length === 0) {
+      return super.query(request);
+    }
+
+    const observables: Array<Observable<DataQueryResponse>> = [];
+    const others = request.targets.filter((t) => !streamed.includes(t));
+    if (others.length > 0) {
+      observables.push(super.query({ ...request, targets: others }));
+    }
+    for (const target of streamed) {
+      const query = this.applyTemplateVariables({ ...target }, request.scopedVars) as MyQuery;
+      const period = streamPeriods.includes(query.period || '') ? query.period : '60';
+      for (const resourceId of splitValues(query.resourceId)) {
+        for (const metricName of splitValues(query.metricName)) {
+          // the stream path is metric/<projectId>/<region>/<resourceType>/<resourceId>/<metricName>/<period>
+          const path = [
+            'metric',
+            query.projectId || 'default',
+            query.region,
+            query.resourceType,
+            resourceId,
+            metricName,
+            period,
+          ];
+          observables.push(
+            getGrafanaLiveSrv().getDataStream({
+              addr: { scope: LiveChannelScope.DataSource, namespace: this.uid, path: path.join('/') },
+            })
+          );
+        }
+      }
+    }
+    return merge(...observables);
+  }
+
   async metricFindQuery(query: string, options?: any) {
     if (query) {
       let obj: any;
@@ -38,19 +92,66 @@ export class DataSource extends DataSourceWithBackend<MyQuery, MyDataSourceOptio
         Limit: obj.Limit,
         Offset: obj.Offset,
         ULBId: obj.ULBId,
+        InstanceId: obj.InstanceId,
+        ClusterId: obj.ClusterId,
+        UGNId: obj.UGNId,
         ClassType: obj.ClassType,
+        NoCache: obj.NoCache,
       };
 
       let respArr: Array<{ text: any; label: any; value: any }> = [];
-      await this.getResource('generic_api', param).then((response: any) => {
-        if (response instanceof Array) {
-          Array.prototype.forEach.call(response || [], (v) => {
-            respArr.push({ text: v, value: v, label: v });
-          });
+      await this.getResource('generic_api', param).then(
+        (response: any) => {
+          if (response instanceof Array) {
+            Array.prototype.forEach.call(response || [], (v) => {
+              // the options are {text, value} objects, the plain strings are kept for the compatibility
+              if (typeof v === 'string') {
+                respArr.push({ text: v, value: v, label: v });
+              } else {
+                respArr.push({ text: v.text, value: v.value, label: v.text });
+              }
+            });
+          }
+        },
+        (err: any) => {
+          // the resource API responds the error envelope {code, message, retCode, requestId, action}
+          throw new Error(formatApiError(err.data) || err.message);
         }
-      });
+      );
       return respArr;
     }
     return Promise.resolve([]);
   }
 }
+
+interface ApiError {
+  code?: string;
+  message?: string;
+  retCode?: number;
+  requestId?: string;
+  action?: string;
+}
+
+// formatApiError formats the error envelope of the resource API as the readable message.
+export function formatApiError(e?: ApiError) {
+  if (!e || !e.message) {
+    return '';
+  }
+  let message = e.action ? `${e.action}: ${e.message}` : e.message;
+  if (e.retCode) {
+    message += ` (RetCode ${e.retCode})`;
+  }
+  if (e.requestId) {
+    message += ` [RequestId ${e.requestId}]`;
+  }
+  return message;
+}
+
+// splitValues splits the comma separated list or the multi-value variable like {a,b,c}, `*` is not streamed.
+function splitValues(s?: string) {
+  return (s || '')
+    .replace(/^\{(.*)\}$/, '$1')
+    .split(',')
+    .map((v) => v.trim())
+    .filter((v) => v !== '' && v !== '*');
+}
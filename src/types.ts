@@ -6,9 +6,19 @@ export interface MyQuery extends DataQuery {
   resourceType: string;
   metricName: string;
   resourceId: string;
+  period?: string;
+  aggregation?: string;
+  groupBy?: string;
+  alias?: string;
+  format?: string;
+  topN?: number;
+  topReducer?: string;
+  topOrder?: string;
+  stream?: boolean;
   tag: string;
-  limit: number;
-  offset: number;
+  resourceName: string;
+  limit?: number;
+  offset?: number;
   ulbId: string;
   classType: string;
 }
@@ -20,6 +30,17 @@ export type SelectableStrings = Array<SelectableValue<string>>;
  */
 export interface MyDataSourceOptions extends DataSourceJsonData {
   projectId?: string;
+  baseUrl?: string;
+  httpProxy?: string;
+  tlsSkipVerify?: boolean;
+  timeout?: number;
+  serviceTimeouts?: { [service: string]: number };
+  maxResources?: number;
+  cacheTTLs?: { [action: string]: number };
+  rateLimit?: number;
+  maxConcurrency?: number;
+  maxRetries?: number;
+  streamInterval?: number;
 }
 
 /**
@@ -28,4 +49,5 @@ export interface MyDataSourceOptions extends DataSourceJsonData {
 export interface MySecureJsonData {
   publicKey: string;
   privateKey: string;
+  tlsCACert?: string;
 }